@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 
@@ -11,6 +13,11 @@ var version = "unknown"
 
 func main() {
 	if err := cli.Run(version); err != nil {
+		// Interrupted by a signal.
+		if errors.Is(err, context.Canceled) {
+			os.Exit(130)
+		}
+
 		fmt.Printf("error: %s\n", err)
 		os.Exit(1)
 	}
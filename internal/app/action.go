@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/gabefiori/gsp/internal/config"
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/run"
+)
+
+// keyAction returns the action run by the given selector key.
+func (a *App) keyAction(key string) *config.Action {
+	for i := range a.actions {
+		if a.actions[i].Key == key {
+			return &a.actions[i]
+		}
+	}
+
+	return nil
+}
+
+// runAction runs the command of the action in the directory of each entry, one after the other.
+func (a *App) runAction(action *config.Action, entries []finder.Entry) error {
+	for _, e := range entries {
+		if err := run.Command(e.Path, actionArgs(action.Command, e.Path), a.out); err != nil {
+			return fmt.Errorf("action %q: %w", action.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// actionArgs expands the environment variables in the command, then replaces "{path}" with path.
+// Paths are replaced last, so variables in them are kept as is.
+func actionArgs(command []string, path string) []string {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = strings.ReplaceAll(os.Expand(arg, expandVar), "{path}", path)
+	}
+
+	return args
+}
+
+// expandVar returns the value of an environment variable.
+// Special parameters (e.g. "$1", "$@") are kept, for commands like "sh -c".
+func expandVar(name string) string {
+	if c := name[0]; c != '_' && !unicode.IsLetter(rune(c)) {
+		return "$" + name
+	}
+
+	return os.Getenv(name)
+}
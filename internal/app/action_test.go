@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabefiori/gsp/internal/config"
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionArgs(t *testing.T) {
+	t.Setenv("EDITOR", "nvim")
+
+	assert.Equal(t,
+		[]string{"nvim", "--", "/src/$HOME/api", "x/src/$HOME/api", "$1 $@ nvim"},
+		actionArgs([]string{"$EDITOR", "--", "{path}", "x{path}", "$1 $@ ${EDITOR}"}, "/src/$HOME/api"),
+	)
+}
+
+func TestAction(t *testing.T) {
+	tempDir := t.TempDir()
+	binDir := t.TempDir()
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("GREETING", "hello")
+
+	projectDir := filepath.Join(tempDir, "project")
+	assert.NoError(t, os.Mkdir(projectDir, 0755))
+
+	// Accepts the project with ctrl-o.
+	fzf := "#!/bin/sh\necho \"$@\" > \"$0.args\"\nprintf 'ctrl-o\\0'\ntr '\\0' '\\n' | grep -m 1 project | tr '\\n' '\\0'\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(binDir, "fzf"), []byte(fzf), 0755))
+
+	actions := []config.Action{
+		{Name: "open", Command: []string{"sh", "-c", `echo "$GREETING $1 $(pwd)"`, "sh", "{path}"}, Key: "ctrl-o"},
+		{Name: "fail", Command: []string{"false"}},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		expected string
+		err      string
+	}{
+		{
+			name:     "Key",
+			cfg:      config.Config{Selector: "fzf"},
+			expected: "hello " + projectDir + " " + projectDir + "\n",
+		},
+		{
+			name:     "Flag",
+			cfg:      config.Config{Selector: "fzf", Filter: "project", Action: "open"},
+			expected: "hello " + projectDir + " " + projectDir + "\n",
+		},
+		{
+			name: "Failed",
+			cfg:  config.Config{Selector: "fzf", Filter: "project", Action: "fail"},
+			err:  `action "fail": exit status 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Actions = actions
+			cfg.Sources = []finder.Source{{OriginalPath: tempDir, Depth: 1}}
+
+			a, err := New(&cfg)
+			assert.NoError(t, err)
+
+			out := new(bytes.Buffer)
+			a.out = out
+
+			err = a.Run(context.Background())
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	args, err := os.ReadFile(filepath.Join(binDir, "fzf.args"))
+	assert.NoError(t, err)
+	assert.Equal(t, "--read0 --print0 --expect ctrl-o\n", string(args))
+}
@@ -2,15 +2,22 @@ package app
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	"github.com/gabefiori/gsp/internal/cache"
 	"github.com/gabefiori/gsp/internal/config"
 	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/history"
 	"github.com/gabefiori/gsp/internal/selector"
+	"github.com/gabefiori/gsp/internal/shellwords"
 	"github.com/mitchellh/go-homedir"
 )
 
@@ -20,18 +27,98 @@ const (
 	ModeSelector Mode = iota
 	ModeList
 	ModeMeasure
+	ModeFilter
 )
 
 type App struct {
-	// Channel to receive output (string) from the finder.
-	// This channel is also passed to the selector to populate its input.
-	ch           chan string
+	// Channel to receive the entries found by the finder.
+	// Their paths are passed to the selector to populate its input.
+	ch chan finder.Entry
+
+	// Entries passed to the selector, by line, so selections are mapped back to them.
+	shown   map[string]finder.Entry
+	shownMu sync.Mutex
+
+	// Lines shared by several entries, which happens when selectors without labels are given
+	// the same display label for different paths. Selecting them is an error.
+	ambiguous map[string]struct{}
+
+	// Template of the labels displayed in the selector. When nil, paths are displayed.
+	display *template.Template
+
+	// Whether the selector receives both the path and the label of each entry.
+	labeled bool
+
+	// Entries read by the modes: the entries of ch, with their git state when requested.
+	results chan finder.Entry
+
+	// Whether the git state of the entries is read, and displayed next to their paths in the selector.
+	git      bool
+	annotate bool
+
+	// Channel to receive errors from the finder.
+	// Errors are collected into errs until the channel is closed, which closes errDone.
+	errCh   chan error
+	errs    []error
+	errMu   sync.Mutex
+	errDone chan struct{}
+
+	// Destination of the results. Defaults to stdout.
+	out io.Writer
+
 	home         string
 	sources      []finder.Source
 	selectorType selector.Type
+	selectorArgs []string
+	selectorCmd  []string
+	preview      bool
+	multi        bool
 	sortType     finder.SortType
+	dedupe       finder.DedupeMode
+	threads      int
 	expandOutput bool
+	format       Format
+	tmpl         *template.Template
+
+	// Terminate entries with NUL instead of newline.
+	print0 bool
+
+	// Fail on the first finder error instead of printing warnings.
+	strict bool
+
+	// Maximum duration for the finder. Zero means no limit.
+	timeout time.Duration
+
+	// Stops the finder and the selector. Set for each run.
+	cancel context.CancelFunc
+
+	// Closed once the mode stops reading from ch (e.g. after a selection).
+	consumed chan struct{}
+
+	// Feed results from the cache, refreshing it in the background.
+	useCache bool
+	cacheKey string
+	cache    cacheState
+
+	// Selections, used to rank entries by frecency. Loaded on first use, see [App.loadHistory].
+	history     *history.History
+	historyOnce sync.Once
+
+	// Query for the filter mode, and whether to only print the best match.
+	filter string
+	first  bool
 	Mode
+
+	// Actions run with a key of the selector, and the one run instead of printing the selection, if any.
+	actions []config.Action
+	action  *config.Action
+
+	// Keys of the actions, passed to the selector.
+	expect []string
+
+	// Called with the selected entries (or the filter matches) instead of printing them.
+	// Paths are expanded. Optional.
+	OnSelect func(ctx context.Context, entries []finder.Entry) error
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -40,13 +127,72 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, err
 	}
 
-	st, err := selector.TypeFromStr(cfg.Selector)
+	// A custom selector command replaces the selector type.
+	var st selector.Type
+	if len(cfg.SelectorCmd) > 0 {
+		if cfg.Multi {
+			return nil, errors.New("multi-selection cannot be used with a custom selector command, add its own flag to the command instead")
+		}
+	} else {
+		st, err = selector.TypeFromStr(cfg.Selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	format, err := FormatFromStr(cfg.Format)
 	if err != nil {
 		return nil, err
 	}
 
+	var tmpl *template.Template
+	if cfg.FormatTemplate != "" {
+		if format != FormatText {
+			return nil, errors.New("a format template cannot be used with the json formats")
+		}
+
+		tmpl, err = parseTemplate(cfg.FormatTemplate)
+		if err != nil {
+			return nil, err
+		}
+
+		format = FormatTemplate
+	}
+
+	if cfg.Print0 && (format == FormatJSON || format == FormatNDJSON) {
+		return nil, errors.New("NUL-terminated output cannot be used with the json formats")
+	}
+
+	var display *template.Template
+	if cfg.Display != "" {
+		display, err = parseDisplay(cfg.Display, home)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var action *config.Action
+	if cfg.Action != "" {
+		action = cfg.FindAction(cfg.Action)
+	}
+
+	// Only some selectors report which key accepted the selection.
+	var expect []string
+	if len(cfg.SelectorCmd) == 0 && selector.SupportsExpect(st) {
+		for _, a := range cfg.Actions {
+			if a.Key != "" {
+				expect = append(expect, a.Key)
+			}
+		}
+	}
+
+	// Custom selectors may use the lines (e.g. in a preview command), so they are given paths.
+	annotate := cfg.Git && display == nil && len(cfg.SelectorCmd) == 0
+
 	var m Mode
-	if cfg.List {
+	if cfg.Filter != "" || cfg.First {
+		m = ModeFilter
+	} else if cfg.List {
 		m = ModeList
 	} else if cfg.Measure {
 		m = ModeMeasure
@@ -54,93 +200,491 @@ func New(cfg *config.Config) (*App, error) {
 
 	return &App{
 		Mode:         m,
+		out:          os.Stdout,
 		home:         home,
 		sources:      cfg.Sources,
-		ch:           make(chan string, len(cfg.Sources)),
+		ch:           make(chan finder.Entry, len(cfg.Sources)),
+		shown:        make(map[string]finder.Entry),
+		ambiguous:    make(map[string]struct{}),
+		errCh:        make(chan error),
+		errDone:      make(chan struct{}),
+		strict:       cfg.Strict,
+		timeout:      cfg.Timeout,
 		sortType:     finder.SortTypeFromStr(cfg.Sort),
+		dedupe:       cfg.Dedupe,
+		threads:      cfg.Threads,
+		consumed:     make(chan struct{}),
+		useCache:     cfg.Cache,
+		cacheKey:     cacheKey(cfg),
+		filter:       cfg.Filter,
+		first:        cfg.First,
 		selectorType: st,
+		selectorArgs: cfg.SelectorArgs,
+		selectorCmd:  cfg.SelectorCmd,
+		preview:      cfg.Preview,
+		multi:        cfg.Multi,
 		expandOutput: cfg.ExpandOutput,
+		format:       format,
+		tmpl:         tmpl,
+		print0:       cfg.Print0,
+		display:      display,
+		labeled:      (display != nil || annotate) && len(cfg.SelectorCmd) == 0 && selector.SupportsLabels(st),
+		git:          cfg.Git,
+		annotate:     annotate,
+		actions:      cfg.Actions,
+		action:       action,
+		expect:       expect,
 	}, nil
 }
 
 // Run executes the main logic of the application.
-func (a *App) Run() error {
+// Once it returns, or ctx is done, the finder stops walking.
+func (a *App) Run(ctx context.Context) error {
 	measureStart := time.Now()
 
-	go finder.Run(&finder.FinderOpts{
-		ResultCh: a.ch,
+	ctx, a.cancel = context.WithCancel(ctx)
+	defer a.cancel()
+
+	// The timeout only stops the walk; entries found so far are kept.
+	var deadline time.Time
+	if a.timeout > 0 {
+		deadline = time.Now().Add(a.timeout)
+	}
+
+	resultCh := a.ch
+
+	var dirCache *finder.DirCache
+	if a.useCache {
+		var err error
+
+		resultCh, dirCache, err = a.startCache(ctx, deadline)
+		if err != nil {
+			return err
+		}
+	}
+
+	go a.collectErrors()
+	go finder.Run(ctx, &finder.FinderOpts{
+		Deadline: deadline,
+		ResultCh: resultCh,
+		DirCache: dirCache,
+		ErrCh:    a.errCh,
 		HomeDir:  a.home,
 		Sources:  a.sources,
 		SortType: a.sortType,
+		Score:    a.score,
 		Unique:   true,
+		Dedupe:   a.dedupe,
+		Strict:   a.strict,
+		Threads:  a.threads,
 	})
 
+	a.results = a.ch
+	if a.git {
+		a.results = a.enrich(ctx, a.ch)
+	}
+
+	var err error
+
 	switch a.Mode {
 	case ModeMeasure:
-		return a.measure(measureStart)
+		err = a.measure(ctx, measureStart)
 	case ModeList:
-		return a.list()
+		err = a.list(ctx)
+	case ModeFilter:
+		err = a.filterEntries(ctx)
 	default:
-		return a.selector()
+		err = a.selector(ctx)
+	}
+
+	close(a.consumed)
+
+	// Exiting is not delayed by the refresh: it is stopped, and only saved if it finished before.
+	// The measure mode already waited for the refresh.
+	if a.useCache && a.Mode != ModeMeasure {
+		a.cancel()
+		a.waitCache()
+	}
+
+	return err
+}
+
+// cacheSource holds the settings of a source affecting the finder results.
+// Unlike [finder.Source], it has no state set while walking, so its key is stable.
+type cacheSource struct {
+	Path         string
+	Depth        uint8
+	Markers      []string
+	StopAtMarker bool
+	Exclude      []string
+	Gitignore    bool
+	Hidden       finder.HiddenMode
+}
+
+// cacheKey identifies the settings affecting the finder results.
+func cacheKey(cfg *config.Config) string {
+	sources := make([]cacheSource, len(cfg.Sources))
+	for i, s := range cfg.Sources {
+		sources[i] = cacheSource{
+			Path:         s.OriginalPath,
+			Depth:        s.Depth,
+			Markers:      s.Markers,
+			StopAtMarker: s.StopAtMarker,
+			Exclude:      s.Exclude,
+			Gitignore:    s.Gitignore,
+			Hidden:       s.Hidden,
+		}
+	}
+
+	return cache.Key(struct {
+		Sources []cacheSource
+		Sort    string
+		Dedupe  finder.DedupeMode
+	}{sources, cfg.Sort, cfg.Dedupe})
+}
+
+func (a *App) collectErrors() {
+	defer close(a.errDone)
+
+	for err := range a.errCh {
+		a.errMu.Lock()
+		a.errs = append(a.errs, err)
+		a.errMu.Unlock()
+
+		// Fail fast, without waiting for the user or the other sources.
+		if a.strict {
+			a.cancel()
+		}
+	}
+}
+
+// finderErr handles the errors collected from the finder.
+// In strict mode, the first error is returned. Otherwise, errors are printed as warnings.
+//
+// When wait is set, it waits for the finder to finish. Otherwise, only the errors received so far are handled.
+func (a *App) finderErr(wait bool) error {
+	if wait {
+		<-a.errDone
+	}
+
+	a.errMu.Lock()
+	defer a.errMu.Unlock()
+
+	if len(a.errs) == 0 {
+		return nil
+	}
+
+	if a.strict {
+		return a.errs[0]
+	}
+
+	for _, err := range a.errs {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", err)
 	}
+
+	return nil
 }
 
-func (a *App) selector() error {
-	s, err := selector.New(a.selectorType)
+func (a *App) selector(ctx context.Context) error {
+	s, err := a.newSelector()
 	if err != nil {
 		return err
 	}
 
-	result, err := s.Run(a.ch)
-	// If the selector is canceled, result will be empty.
-	if err != nil || result == "" {
+	results, err := s.Run(ctx, a.lines(ctx))
+
+	// The selection is not delayed until the finder is done.
+	// Checked first, since a strict error also cancels the selector.
+	if err := a.finderErr(false); err != nil {
 		return err
 	}
 
-	if !a.expandOutput || !strings.HasPrefix(result, "~") {
-		_, err = os.Stdout.WriteString(result)
+	if err != nil {
 		return err
 	}
 
-	_, err = os.Stdout.WriteString(a.home + result[1:])
+	// The key accepting the selection comes first.
+	action := a.action
+	if len(a.expect) > 0 && len(results) > 0 {
+		if key := results[0]; key != "" {
+			action = a.keyAction(key)
+		}
+
+		results = results[1:]
+	}
+
+	// If the selector is canceled, results will be empty.
+	if len(results) == 0 {
+		return nil
+	}
+
+	entries := make([]finder.Entry, len(results))
+	for i, r := range results {
+		e, err := a.shownEntry(r)
+		if err != nil {
+			return err
+		}
+
+		entries[i] = e
+	}
+
+	a.record(finder.Paths(entries)...)
+
+	return a.output(ctx, entries, action)
+}
+
+// output runs the action or the handler with the selected entries or, without them, prints the entries.
+func (a *App) output(ctx context.Context, entries []finder.Entry, action *config.Action) error {
+	if action != nil || a.OnSelect != nil {
+		expanded := make([]finder.Entry, len(entries))
+		for i, e := range entries {
+			e.Path = a.expandHome(e.Path)
+			expanded[i] = e
+		}
+
+		if action != nil {
+			return a.runAction(action, expanded)
+		}
+
+		return a.OnSelect(ctx, expanded)
+	}
+
+	buf := new(bytes.Buffer)
+	enc := a.newEncoder(buf, a.expandOutput)
+
+	for _, e := range entries {
+		if err := enc.encode(e); err != nil {
+			return err
+		}
+	}
+
+	enc.close()
+
+	_, err := io.Copy(a.out, buf)
 	return err
 }
 
-func (a *App) measure(start time.Time) error {
+func (a *App) newSelector() (selector.Selector, error) {
+	// Custom commands are given their own multi-select flag, if any.
+	if len(a.selectorCmd) > 0 {
+		return selector.NewCustom(a.selectorCmd)
+	}
+
+	args := a.selectorArgs
+
+	// Added first, so the selector arguments can override the preview settings.
+	if a.preview {
+		exe, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+
+		field := "{}"
+		if a.labeled {
+			field = "{1}"
+		}
+
+		previewCmd := shellwords.Quote(exe) + " preview " + field
+		args = append(selector.PreviewArgs(a.selectorType, previewCmd), args...)
+	}
+
+	return selector.New(a.selectorType, selector.Options{
+		Args:    args,
+		Multi:   a.multi,
+		Labeled: a.labeled,
+		Expect:  a.expect,
+	})
+}
+
+// lines forwards the entries to the selector.
+func (a *App) lines(ctx context.Context) chan string {
+	ch := make(chan string, cap(a.ch))
+
+	go func() {
+		defer close(ch)
+
+		for e := range a.results {
+			line := a.line(e)
+
+			a.shownMu.Lock()
+			if prev, ok := a.shown[line]; ok && prev.Path != e.Path {
+				a.ambiguous[line] = struct{}{}
+			}
+			a.shown[line] = e
+			a.shownMu.Unlock()
+
+			select {
+			case ch <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// shownEntry returns the entry passed to the selector as the given line.
+// Custom selectors may print anything, so unknown lines are returned as entries of their own.
+// Lines shared by several entries cannot be mapped back, so they are an error.
+func (a *App) shownEntry(line string) (finder.Entry, error) {
+	a.shownMu.Lock()
+	defer a.shownMu.Unlock()
+
+	if _, ok := a.ambiguous[line]; ok {
+		return finder.Entry{}, fmt.Errorf("selected label %q matches several entries, make the display labels unique", line)
+	}
+
+	if e, ok := a.shown[line]; ok {
+		return e, nil
+	}
+
+	return finder.Entry{Path: line}, nil
+}
+
+// newEncoder returns an encoder writing entries to buf in the output format.
+// When expand is set, the text format writes the paths with the home directory expanded.
+func (a *App) newEncoder(buf *bytes.Buffer, expand bool) *encoder {
+	text := func(e finder.Entry) string {
+		return e.Path
+	}
+
+	if expand {
+		text = func(e finder.Entry) string {
+			return a.expandHome(e.Path)
+		}
+	}
+
+	sep := byte('\n')
+	if a.print0 {
+		sep = 0
+	}
+
+	return &encoder{
+		format: a.format,
+		buf:    buf,
+		sep:    sep,
+		tmpl:   a.tmpl,
+		text:   text,
+		expand: a.expandHome,
+		label:  a.label,
+	}
+}
+
+// expandHome replaces a leading "~" with the user's home directory.
+func (a *App) expandHome(p string) string {
+	if !strings.HasPrefix(p, "~") {
+		return p
+	}
+
+	return a.home + p[1:]
+}
+
+// loadHistory loads the history, once, when entries are ranked or selected.
+// An unreadable history is not fatal, so it is printed as a warning and handled as an empty one.
+func (a *App) loadHistory() *history.History {
+	a.historyOnce.Do(func() {
+		path, err := history.Path()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to load history: %s\n", err)
+			return
+		}
+
+		a.history, err = history.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to load history, ignoring it: %s\n", err)
+		}
+	})
+
+	return a.history
+}
+
+// score returns the frecency of an entry.
+func (a *App) score(entry string) float64 {
+	h := a.loadHistory()
+	if h == nil {
+		return 0
+	}
+
+	return h.Score(a.expandHome(entry), time.Now())
+}
+
+// record adds the selected entries to the history.
+// Failing to save the history is not fatal, so it is printed as a warning.
+func (a *App) record(entries ...string) {
+	h := a.loadHistory()
+	if h == nil {
+		return
+	}
+
+	now := time.Now()
+
+	for _, e := range entries {
+		h.Add(a.expandHome(e), now)
+	}
+
+	if err := h.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save history: %s\n", err)
+	}
+}
+
+func (a *App) measure(ctx context.Context, start time.Time) error {
 	var count int
 
-	for range a.ch {
+	for range a.results {
 		count++
 	}
 
 	measureEnd := time.Since(start).String()
 	msg := fmt.Sprintf("Took %s (%d projects)", measureEnd, count)
 
-	_, err := os.Stdout.WriteString(msg)
+	if a.useCache {
+		a.waitCache()
+
+		// On a hit, the cached entries were counted, instead of the refreshed ones.
+		msg = fmt.Sprintf("Took %s (%d projects, %s)", measureEnd, a.cache.count, a.cacheSummary())
+	}
+
+	if err := a.finderErr(true); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(a.out, msg)
 	return err
 }
 
-func (a *App) list() error {
-	size, count := 50, 0
+func (a *App) list(ctx context.Context) error {
+	size := 50
 	buf := new(bytes.Buffer)
+	enc := a.newEncoder(buf, false)
 
-	for r := range a.ch {
-		if _, err := buf.WriteString(r + "\n"); err != nil {
+	for r := range a.results {
+		if err := enc.encode(r); err != nil {
 			return err
 		}
 
-		count++
-
-		if count >= size {
-			if _, err := io.Copy(os.Stdout, buf); err != nil {
+		if enc.count%size == 0 {
+			if _, err := io.Copy(a.out, buf); err != nil {
 				return err
 			}
 
 			buf.Reset()
-			count = 0
 		}
 	}
 
-	_, err := io.Copy(os.Stdout, buf)
-	return err
+	enc.close()
+
+	if _, err := io.Copy(a.out, buf); err != nil {
+		return err
+	}
+
+	if err := a.finderErr(true); err != nil {
+		return err
+	}
+
+	return ctx.Err()
 }
@@ -0,0 +1,334 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabefiori/gsp/internal/config"
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, name := range []string{"api-gateway", "rapid", "web"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(tempDir, name), 0755))
+	}
+
+	tests := []struct {
+		name     string
+		filter   string
+		first    bool
+		expected string
+		err      bool
+	}{
+		{
+			name:   "Ranked",
+			filter: "api",
+			expected: filepath.Join(tempDir, "api-gateway") + "\n" +
+				filepath.Join(tempDir, "rapid") + "\n",
+		},
+		{
+			name:     "First",
+			filter:   "api",
+			first:    true,
+			expected: filepath.Join(tempDir, "api-gateway") + "\n",
+		},
+		{
+			name:     "Single term",
+			filter:   "web",
+			expected: filepath.Join(tempDir, "web") + "\n",
+		},
+		{
+			name:   "No match",
+			filter: "billing",
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New(&config.Config{
+				Selector: "fzf",
+				Filter:   tt.filter,
+				First:    tt.first,
+				Sources:  []finder.Source{{OriginalPath: tempDir, Depth: 1}},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, ModeFilter, a.Mode)
+
+			out := new(bytes.Buffer)
+			a.out = out
+
+			err = a.Run(context.Background())
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestOnSelect(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for _, name := range []string{"api-gateway", "rapid"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(tempDir, name), 0755))
+	}
+
+	a, err := New(&config.Config{
+		Selector: "fzf",
+		Filter:   "api",
+		Sources:  []finder.Source{{OriginalPath: tempDir, Depth: 1}},
+	})
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	a.out = out
+
+	var selected []string
+	a.OnSelect = func(ctx context.Context, entries []finder.Entry) error {
+		selected = finder.Paths(entries)
+		return nil
+	}
+
+	assert.NoError(t, a.Run(context.Background()))
+	assert.Equal(t, []string{filepath.Join(tempDir, "api-gateway"), filepath.Join(tempDir, "rapid")}, selected)
+	assert.Empty(t, out.String())
+}
+
+func TestListFormat(t *testing.T) {
+	tempDir := t.TempDir()
+
+	projectDir := filepath.Join(tempDir, "project")
+	linkDir := filepath.Join(tempDir, "link")
+
+	assert.NoError(t, os.Mkdir(projectDir, 0755))
+	assert.NoError(t, os.Symlink(projectDir, linkDir))
+
+	root := `{"path":"` + tempDir + `","display":"` + tempDir + `","source":"` + tempDir + `","depth":0,"isSymlink":false}`
+	project := `{"path":"` + projectDir + `","display":"` + projectDir + `","source":"` + tempDir + `","depth":1,"isSymlink":false}`
+	link := `{"path":"` + linkDir + `","display":"` + linkDir + `","source":"` + tempDir + `","depth":1,"isSymlink":true}`
+
+	tests := []struct {
+		format   string
+		expected string
+		err      bool
+	}{
+		{format: "text", expected: tempDir + "\n" + linkDir + "\n" + projectDir + "\n"},
+		{format: "json", expected: "[" + root + "," + link + "," + project + "]\n"},
+		{format: "ndjson", expected: root + "\n" + link + "\n" + project + "\n"},
+		{format: "yaml", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			a, err := New(&config.Config{
+				Selector: "fzf",
+				List:     true,
+				Format:   tt.format,
+				Sort:     "asc",
+				Sources:  []finder.Source{{OriginalPath: tempDir, Depth: 1}},
+			})
+
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			out := new(bytes.Buffer)
+			a.out = out
+
+			assert.NoError(t, a.Run(context.Background()))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestSelectorFormatTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	assert.NoError(t, os.Mkdir(filepath.Join(tempDir, "project"), 0755))
+
+	a, err := New(&config.Config{
+		SelectorCmd:    []string{"grep", "-m", "1", "project"},
+		FormatTemplate: "{{.Name}} {{.Depth}}",
+		Sources:        []finder.Source{{OriginalPath: tempDir, Depth: 1}},
+	})
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	a.out = out
+
+	assert.NoError(t, a.Run(context.Background()))
+	assert.Equal(t, "project 1\n", out.String())
+}
+
+func TestSelectorDisplay(t *testing.T) {
+	tempDir := t.TempDir()
+	binDir := t.TempDir()
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	projectDir := filepath.Join(tempDir, "project")
+	assert.NoError(t, os.Mkdir(projectDir, 0755))
+
+	// Records its arguments, and selects the first entry containing the label.
+	fzf := "#!/bin/sh\necho \"$@\" > \"$0.args\"\ntr '\\0' '\\n' | grep -m 1 'project \\[src\\]' | tr '\\n' '\\0'\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(binDir, "fzf"), []byte(fzf), 0755))
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+		args string
+	}{
+		{
+			name: "Labeled",
+			cfg:  config.Config{Selector: "fzf"},
+			args: "--read0 --print0 --delimiter \t --with-nth 2..\n",
+		},
+		{
+			name: "Custom selector",
+			cfg:  config.Config{SelectorCmd: []string{"grep", "-m", "1", `project \[src\]`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove(filepath.Join(binDir, "fzf.args"))
+
+			cfg := tt.cfg
+			cfg.Display = "{{.Name}} [src]"
+			cfg.Sources = []finder.Source{{OriginalPath: tempDir, Depth: 1}}
+
+			a, err := New(&cfg)
+			assert.NoError(t, err)
+
+			out := new(bytes.Buffer)
+			a.out = out
+
+			assert.NoError(t, a.Run(context.Background()))
+			assert.Equal(t, projectDir+"\n", out.String())
+
+			if tt.args != "" {
+				args, err := os.ReadFile(filepath.Join(binDir, "fzf.args"))
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args, string(args))
+			}
+		})
+	}
+
+	t.Run("Shared label", func(t *testing.T) {
+		dupDir := t.TempDir()
+		for _, dir := range []string{"personal/api", "work/api", "work/web"} {
+			assert.NoError(t, os.MkdirAll(filepath.Join(dupDir, dir), 0755))
+		}
+
+		tests := []struct {
+			selected string
+			expected string
+		}{
+			{selected: "web", expected: filepath.Join(dupDir, "work", "web") + "\n"},
+			{selected: "api"},
+		}
+
+		for _, tt := range tests {
+			a, err := New(&config.Config{
+				SelectorCmd: []string{"grep", "-m", "1", "-x", tt.selected},
+				Display:     "{{.Name}}",
+				Sort:        "asc",
+				Sources:     []finder.Source{{OriginalPath: dupDir, Depth: 2}},
+			})
+			assert.NoError(t, err)
+
+			out := new(bytes.Buffer)
+			a.out = out
+
+			err = a.Run(context.Background())
+			if tt.expected == "" {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.expected, out.String())
+		}
+	})
+
+	t.Run("Invalid display", func(t *testing.T) {
+		_, err := New(&config.Config{Selector: "fzf", Display: "{{.Branch}}"})
+		assert.Error(t, err)
+	})
+}
+
+func TestPrint0(t *testing.T) {
+	tempDir := t.TempDir()
+
+	oddDir := filepath.Join(tempDir, "odd\nname")
+	assert.NoError(t, os.Mkdir(oddDir, 0755))
+
+	a, err := New(&config.Config{
+		Selector: "fzf",
+		List:     true,
+		Print0:   true,
+		Sort:     "asc",
+		Sources:  []finder.Source{{OriginalPath: tempDir, Depth: 1}},
+	})
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	a.out = out
+
+	assert.NoError(t, a.Run(context.Background()))
+	assert.Equal(t, tempDir+"\x00"+oddDir+"\x00", out.String())
+
+	_, err = New(&config.Config{Selector: "fzf", Print0: true, Format: "ndjson"})
+	assert.Error(t, err)
+}
+
+func TestCorruptHistory(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+
+	historyPath := filepath.Join(dataDir, "gsp", "history")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(historyPath), 0755))
+	assert.NoError(t, os.WriteFile(historyPath, []byte("invalid\n"), 0644))
+
+	for _, sort := range []string{"asc", "frecency"} {
+		a, err := New(&config.Config{
+			Selector: "fzf",
+			List:     true,
+			Sort:     sort,
+			Sources:  []finder.Source{{OriginalPath: tempDir, Depth: 0}},
+		})
+		assert.NoError(t, err)
+
+		out := new(bytes.Buffer)
+		a.out = out
+
+		// Ranking entries with an unreadable history falls back to an empty one.
+		assert.NoError(t, a.Run(context.Background()))
+		assert.Equal(t, tempDir+"\n", out.String())
+		assert.Equal(t, sort == "frecency", a.history != nil)
+	}
+}
+
+func TestMultiCustomSelector(t *testing.T) {
+	_, err := New(&config.Config{SelectorCmd: []string{"peco"}, Multi: true})
+	assert.Error(t, err)
+
+	_, err = New(&config.Config{SelectorCmd: []string{"peco"}})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gabefiori/gsp/internal/cache"
+	"github.com/gabefiori/gsp/internal/finder"
+)
+
+// cacheState holds the state of the result cache during a run.
+type cacheState struct {
+	path string
+	hit  bool
+
+	// Listings of the walked directories, shared with the finder.
+	dirs *finder.DirCache
+
+	// Closed once the refresh is done and the cache is saved.
+	done chan struct{}
+	err  error
+
+	// Number of entries found by the refresh.
+	count int
+}
+
+// startCache loads the cache and returns the channel the finder must send its results to.
+//
+// On a hit, cached results are fed to the selector right away,
+// while the finder refreshes the cache in the background, only reading the changed directories.
+// On a miss, the finder results are forwarded as usual and saved for the next run.
+func (a *App) startCache(ctx context.Context, deadline time.Time) (chan finder.Entry, *finder.DirCache, error) {
+	dir, err := cache.Dir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	a.cache.path = filepath.Join(dir, a.cacheKey)
+	a.cache.done = make(chan struct{})
+
+	// An invalid cache is handled as a miss, so it is replaced.
+	data, err := cache.Load(a.cache.path)
+	a.cache.hit = err == nil
+
+	var prev map[string]finder.CachedDir
+	if a.cache.hit {
+		prev = data.Dirs
+
+		// Selections and changes made since the cache was saved change the order.
+		switch a.sortType {
+		case finder.FrecencySort:
+			finder.Sort(data.Results, a.sortType, a.score)
+		case finder.MTimeSort, finder.MTimeAscSort, finder.CommitSort, finder.CommitAscSort:
+			finder.ReadTimes(data.Results, a.sortType, a.home, a.threads)
+			finder.Sort(data.Results, a.sortType, a.score)
+		}
+
+		go a.feedCache(data.Results)
+	}
+
+	a.cache.dirs = finder.NewDirCache(prev)
+	refreshCh := make(chan finder.Entry, cap(a.ch))
+
+	go a.refreshCache(ctx, deadline, refreshCh)
+
+	return refreshCh, a.cache.dirs, nil
+}
+
+func (a *App) feedCache(results []finder.Entry) {
+	defer close(a.ch)
+
+	for _, r := range results {
+		select {
+		case a.ch <- r:
+		case <-a.consumed:
+			return
+		}
+	}
+}
+
+func (a *App) refreshCache(ctx context.Context, deadline time.Time, refreshCh chan finder.Entry) {
+	defer close(a.cache.done)
+
+	var results []finder.Entry
+	forward := !a.cache.hit
+
+	for r := range refreshCh {
+		results = append(results, r)
+
+		if !forward {
+			continue
+		}
+
+		select {
+		case a.ch <- r:
+		case <-a.consumed:
+			// Keep refreshing, even though no one is reading the results anymore.
+			forward = false
+		}
+	}
+
+	if !a.cache.hit {
+		close(a.ch)
+	}
+
+	a.cache.count = len(results)
+
+	// Partial results (e.g. after a timeout, an interruption or a selection made during the walk) are not saved.
+	if ctx.Err() != nil || (!deadline.IsZero() && time.Now().After(deadline)) {
+		return
+	}
+
+	a.cache.err = cache.Save(a.cache.path, &cache.Data{
+		Results: results,
+		Dirs:    a.cache.dirs.Dirs(),
+	})
+}
+
+// waitCache waits for the refresh. Failing to save the cache is not fatal, so it is printed as a warning.
+func (a *App) waitCache() {
+	<-a.cache.done
+
+	if a.cache.err != nil && !errors.Is(a.cache.err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "warning: failed to save cache: %s\n", a.cache.err)
+	}
+}
+
+// cacheSummary describes the cache usage for the measure mode.
+func (a *App) cacheSummary() string {
+	status := "miss"
+	if a.cache.hit {
+		status = "hit"
+	}
+
+	return fmt.Sprintf("cache %s, %d directories read", status, a.cache.dirs.Reads())
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabefiori/gsp/internal/config"
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheKey(t *testing.T) {
+	tempDir := t.TempDir()
+
+	newConfig := func(markers ...string) *config.Config {
+		return &config.Config{
+			Sort:    "asc",
+			Sources: []finder.Source{{OriginalPath: tempDir, Depth: 1, Markers: markers, Exclude: []string{"vendor/"}}},
+		}
+	}
+
+	cfg := newConfig(".git")
+	key := cacheKey(cfg)
+
+	// Walking a source sets its internal state, which must not change the key.
+	resultCh := make(chan finder.Entry, 1)
+	assert.NoError(t, cfg.Sources[0].Find(context.Background(), resultCh, nil, func(s string) string { return s }))
+
+	assert.Equal(t, key, cacheKey(cfg))
+	assert.Equal(t, key, cacheKey(newConfig(".git")))
+	assert.NotEqual(t, key, cacheKey(newConfig("go.mod")))
+}
+
+func TestCacheMeasure(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	assert.NoError(t, os.Mkdir(filepath.Join(tempDir, "api"), 0755))
+
+	measure := func() string {
+		a, err := New(&config.Config{
+			Selector: "fzf",
+			Measure:  true,
+			Cache:    true,
+			Sources:  []finder.Source{{OriginalPath: tempDir, Depth: 1}},
+		})
+		assert.NoError(t, err)
+
+		out := new(bytes.Buffer)
+		a.out = out
+
+		assert.NoError(t, a.Run(context.Background()))
+		return out.String()
+	}
+
+	assert.Contains(t, measure(), "(2 projects, cache miss")
+
+	// The refreshed entries are counted, instead of the cached ones.
+	assert.NoError(t, os.Mkdir(filepath.Join(tempDir, "web"), 0755))
+	assert.Contains(t, measure(), "(3 projects, cache hit")
+}
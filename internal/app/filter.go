@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/fuzzy"
+)
+
+// filterEntries outputs the entries matching the filter query, best matches first, without a selector.
+// When only the first match is requested, it is expanded like a selection.
+func (a *App) filterEntries(ctx context.Context) error {
+	var entries []finder.Entry
+	for e := range a.results {
+		entries = append(entries, e)
+	}
+
+	if err := a.finderErr(true); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	results := fuzzy.Rank(a.filter, finder.Paths(entries))
+	if len(results) == 0 {
+		return fmt.Errorf("no entries matching %q", a.filter)
+	}
+
+	if a.first {
+		results = results[:1]
+	}
+
+	matches := make([]finder.Entry, len(results))
+	for i, r := range results {
+		matches[i] = entries[r.Index]
+	}
+
+	return a.output(ctx, matches, a.action)
+}
@@ -0,0 +1,312 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/git"
+)
+
+// Format is the output format of the entries.
+type Format int8
+
+const (
+	// One path per line.
+	FormatText Format = iota
+
+	// A JSON array of objects.
+	FormatJSON
+
+	// One JSON object per line.
+	FormatNDJSON
+
+	// A Go template executed for each entry, one per line.
+	FormatTemplate
+)
+
+func FormatFromStr(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "ndjson":
+		return FormatNDJSON, nil
+	default:
+		return FormatText, fmt.Errorf("invalid format %q", s)
+	}
+}
+
+// jsonEntry is the object written for each entry by the JSON formats.
+type jsonEntry struct {
+	// Absolute path of the directory.
+	Path string `json:"path"`
+
+	// Label displayed in the selector.
+	Display string `json:"display"`
+
+	Source    string `json:"source"`
+	Depth     uint8  `json:"depth"`
+	IsSymlink bool   `json:"isSymlink"`
+
+	// State of the repository, when git information is read and the directory is one.
+	Git *jsonGit `json:"git,omitempty"`
+}
+
+// jsonGit is the git state of an entry written by the JSON formats.
+type jsonGit struct {
+	Branch string `json:"branch"`
+	Commit string `json:"commit"`
+
+	// Committer date of the commit, in RFC 3339 format.
+	CommitTime string `json:"commitTime,omitempty"`
+
+	Dirty  bool `json:"dirty"`
+	Ahead  int  `json:"ahead"`
+	Behind int  `json:"behind"`
+}
+
+func newJSONGit(info *git.Info) *jsonGit {
+	if info == nil {
+		return nil
+	}
+
+	j := &jsonGit{
+		Branch: info.Branch,
+		Commit: info.Commit,
+		Dirty:  info.Dirty,
+		Ahead:  info.Ahead,
+		Behind: info.Behind,
+	}
+
+	if !info.CommitTime.IsZero() {
+		j.CommitTime = info.CommitTime.UTC().Format(time.RFC3339)
+	}
+
+	return j
+}
+
+// templateEntry is the data of the output template.
+type templateEntry struct {
+	// Base name of the directory.
+	Name string
+
+	// Absolute path of the directory.
+	Path string
+
+	// Path as found, e.g. with "~" for the home directory.
+	Display string
+
+	// Path of the source, as configured, and the path of the directory relative to it.
+	Source string
+	Rel    string
+
+	Depth   uint8
+	Symlink bool
+
+	// Git state read for the entry, if any.
+	git *git.Info
+}
+
+// templateGit is the git information of an entry. Fields are empty when it is not a repository.
+type templateGit struct {
+	IsRepo bool
+	Branch string
+
+	// Commit hash, when HEAD is detached.
+	Commit string
+
+	// Hash and committer date of the commit checked out.
+	Hash       string
+	CommitTime time.Time
+
+	// Only set when git information is read for all entries (see [config.Config.Git]).
+	Dirty  bool
+	Ahead  int
+	Behind int
+}
+
+// Git returns the git information of the entry. Only called when the template uses it,
+// so the branch and the last commit are read on demand when they were not read already.
+func (t templateEntry) Git() templateGit {
+	info := t.git
+	if info == nil {
+		var err error
+
+		info, err = git.ReadHead(t.Path)
+		if err != nil {
+			return templateGit{}
+		}
+	}
+
+	g := templateGit{
+		IsRepo:     true,
+		Branch:     info.Branch,
+		Hash:       info.Commit,
+		CommitTime: info.CommitTime,
+		Dirty:      info.Dirty,
+		Ahead:      info.Ahead,
+		Behind:     info.Behind,
+	}
+
+	if info.Branch == "" {
+		g.Commit = info.Commit
+	}
+
+	return g
+}
+
+// escapes are the backslash escapes supported in the text of output templates,
+// so that "\t" can be used without shell specific quoting.
+var escapes = strings.NewReplacer(`\\`, `\`, `\t`, "\t", `\n`, "\n")
+
+// parseTemplate parses an output template, e.g. "{{.Name}}\t{{.Path}}".
+func parseTemplate(s string) (*template.Template, error) {
+	t, err := template.New("format").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	unescape(t.Tree.Root)
+
+	return t, nil
+}
+
+// unescape replaces the escapes in the text nodes of a template, leaving the actions as is.
+func unescape(n parse.Node) {
+	switch n := n.(type) {
+	case *parse.TextNode:
+		n.Text = []byte(escapes.Replace(string(n.Text)))
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			unescape(child)
+		}
+	case *parse.IfNode:
+		unescape(n.List)
+		unescape(n.ElseList)
+	case *parse.RangeNode:
+		unescape(n.List)
+		unescape(n.ElseList)
+	case *parse.WithNode:
+		unescape(n.List)
+		unescape(n.ElseList)
+	}
+}
+
+// encoder writes entries to a buffer in the output format.
+type encoder struct {
+	format Format
+	buf    *bytes.Buffer
+
+	// Terminator of the entries written by the text and template formats.
+	sep byte
+
+	// Template executed by [FormatTemplate].
+	tmpl *template.Template
+
+	// Returns the path written by the text format.
+	text func(finder.Entry) string
+
+	// Returns the absolute path of an entry.
+	expand func(string) string
+
+	// Returns the label displayed in the selector.
+	label func(finder.Entry) string
+
+	count int
+}
+
+// encode writes an entry to the buffer.
+func (e *encoder) encode(entry finder.Entry) error {
+	defer func() { e.count++ }()
+
+	switch e.format {
+	case FormatText:
+		e.buf.WriteString(e.text(entry))
+		e.buf.WriteByte(e.sep)
+
+		return nil
+	case FormatTemplate:
+		if err := e.tmpl.Execute(e.buf, newTemplateEntry(entry, e.expand)); err != nil {
+			return err
+		}
+
+		e.buf.WriteByte(e.sep)
+
+		return nil
+	}
+
+	data, err := json.Marshal(jsonEntry{
+		Path:      e.expand(entry.Path),
+		Display:   e.label(entry),
+		Source:    entry.Source,
+		Depth:     entry.Depth,
+		IsSymlink: entry.Symlink,
+		Git:       newJSONGit(entry.Git),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if e.format == FormatJSON {
+		if e.count == 0 {
+			e.buf.WriteByte('[')
+		} else {
+			e.buf.WriteByte(',')
+		}
+	}
+
+	e.buf.Write(data)
+
+	if e.format == FormatNDJSON {
+		e.buf.WriteByte('\n')
+	}
+
+	return nil
+}
+
+// newTemplateEntry returns the template data of an entry. expand returns absolute paths.
+func newTemplateEntry(entry finder.Entry, expand func(string) string) templateEntry {
+	p := expand(entry.Path)
+
+	rel, err := filepath.Rel(expand(entry.Source), p)
+	if err != nil {
+		rel = p
+	}
+
+	return templateEntry{
+		Name:    filepath.Base(p),
+		Path:    p,
+		Display: entry.Path,
+		Source:  entry.Source,
+		Rel:     rel,
+		Depth:   entry.Depth,
+		Symlink: entry.Symlink,
+		git:     entry.Git,
+	}
+}
+
+// close terminates the output. The JSON array is written even without entries.
+func (e *encoder) close() {
+	if e.format != FormatJSON {
+		return
+	}
+
+	if e.count == 0 {
+		e.buf.WriteByte('[')
+	}
+
+	e.buf.WriteString("]\n")
+}
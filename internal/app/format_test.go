@@ -0,0 +1,78 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTemplate(t *testing.T) {
+	home := t.TempDir()
+	repo := filepath.Join(home, "src", "repo")
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644))
+
+	entry := finder.Entry{Path: "~/src/repo", Source: "~/src", Depth: 1}
+	a := &App{home: home}
+
+	tests := []struct {
+		name     string
+		tmpl     string
+		expected string
+	}{
+		{
+			name:     "Escapes",
+			tmpl:     `{{.Name}}\t{{.Path}}\\`,
+			expected: "repo\t" + repo + "\\\n",
+		},
+		{
+			name:     "Paths",
+			tmpl:     "{{.Display}} {{.Source}} {{.Rel}} {{.Depth}} {{.Symlink}}",
+			expected: "~/src/repo ~/src repo 1 false\n",
+		},
+		{
+			name:     "Git",
+			tmpl:     `{{.Name}}{{if .Git.IsRepo}} [{{.Git.Branch}}]{{end}}`,
+			expected: "repo [main]\n",
+		},
+		{
+			name:     "Escapes in actions",
+			tmpl:     `{{printf "%s\n" .Name}}\n`,
+			expected: "repo\n\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseTemplate(tt.tmpl)
+			assert.NoError(t, err)
+
+			a.format, a.tmpl = FormatTemplate, tmpl
+
+			buf := new(bytes.Buffer)
+			enc := a.newEncoder(buf, false)
+
+			assert.NoError(t, enc.encode(entry))
+			enc.close()
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := parseTemplate("{{.Name")
+		assert.Error(t, err)
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		tmpl, err := parseTemplate("{{.Branch}}")
+		assert.NoError(t, err)
+
+		a.format, a.tmpl = FormatTemplate, tmpl
+		assert.Error(t, a.newEncoder(new(bytes.Buffer), false).encode(entry))
+	})
+}
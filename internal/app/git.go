@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/git"
+)
+
+// enrich returns the entries of in with their git state, read by as many workers as the finder walks with.
+// Entries are returned in the order they are received, so the sort order is kept.
+func (a *App) enrich(ctx context.Context, in chan finder.Entry) chan finder.Entry {
+	workers := a.threads
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	out := make(chan finder.Entry, cap(in))
+
+	// Results of the entries being read, in order. Its capacity bounds the number of workers.
+	pending := make(chan chan finder.Entry, workers)
+
+	go func() {
+		defer close(pending)
+
+		for e := range in {
+			result := make(chan finder.Entry, 1)
+
+			select {
+			case pending <- result:
+			case <-ctx.Done():
+				return
+			case <-a.consumed:
+				return
+			}
+
+			go func() {
+				// Directories that are not repositories are kept as is.
+				if info, err := git.Read(ctx, a.expandHome(e.Path)); err == nil {
+					e.Git = info
+				}
+
+				result <- e
+			}()
+		}
+	}()
+
+	go func() {
+		defer close(out)
+
+		for result := range pending {
+			select {
+			case out <- <-result:
+			case <-ctx.Done():
+				return
+			case <-a.consumed:
+				return
+			}
+		}
+	}()
+
+	return out
+}
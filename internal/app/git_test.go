@@ -0,0 +1,141 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gabefiori/gsp/internal/config"
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tempDir := t.TempDir()
+	binDir := t.TempDir()
+
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "gsp")
+	t.Setenv("GIT_AUTHOR_EMAIL", "gsp@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "gsp")
+	t.Setenv("GIT_COMMITTER_EMAIL", "gsp@example.com")
+	t.Setenv("GIT_COMMITTER_DATE", "2024-06-01T12:30:00Z")
+
+	// "api" has uncommitted changes, "web" is clean and "docs" is not a repository.
+	for _, name := range []string{"api", "web", "docs"} {
+		dir := filepath.Join(tempDir, name)
+		assert.NoError(t, os.Mkdir(dir, 0755))
+
+		if name == "docs" {
+			continue
+		}
+
+		for _, args := range [][]string{{"init", "-q", "-b", "main"}, {"commit", "-q", "--allow-empty", "-m", "init"}} {
+			out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+			assert.NoError(t, err, string(out))
+		}
+	}
+
+	// Staged, since untracked files do not make the worktree dirty.
+	assert.NoError(t, os.WriteFile(filepath.Join(tempDir, "api", "main.go"), nil, 0644))
+	out, err := exec.Command("git", "-C", filepath.Join(tempDir, "api"), "add", "main.go").CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	newApp := func(cfg config.Config) *App {
+		cfg.Git = true
+		cfg.Sort = "asc"
+		cfg.Sources = []finder.Source{{OriginalPath: tempDir, Depth: 1}}
+
+		a, err := New(&cfg)
+		assert.NoError(t, err)
+
+		return a
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		a := newApp(config.Config{Selector: "fzf", List: true, Format: "ndjson"})
+
+		out := new(bytes.Buffer)
+		a.out = out
+		assert.NoError(t, a.Run(context.Background()))
+
+		var entries []jsonEntry
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			var e jsonEntry
+			assert.NoError(t, json.Unmarshal([]byte(line), &e))
+			entries = append(entries, e)
+		}
+
+		// The order of the entries is kept.
+		assert.Len(t, entries, 4)
+		assert.Equal(t, []string{tempDir, "api", "docs", "web"}, []string{
+			entries[0].Path, filepath.Base(entries[1].Path), filepath.Base(entries[2].Path), filepath.Base(entries[3].Path),
+		})
+
+		assert.Nil(t, entries[0].Git)
+		assert.Nil(t, entries[2].Git)
+
+		api, web := entries[1].Git, entries[3].Git
+		assert.True(t, api.Dirty)
+		assert.False(t, web.Dirty)
+		assert.Equal(t, "main", web.Branch)
+		assert.Len(t, web.Commit, 40)
+		assert.Equal(t, "2024-06-01T12:30:00Z", web.CommitTime)
+	})
+
+	t.Run("Selector", func(t *testing.T) {
+		// Records its input, and selects the entry of "web".
+		fzf := "#!/bin/sh\ntr '\\0' '\\n' | tee \"$0.in\" | grep -m 1 '/web' | tr '\\n' '\\0'\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(binDir, "fzf"), []byte(fzf), 0755))
+
+		a := newApp(config.Config{Selector: "fzf"})
+
+		out := new(bytes.Buffer)
+		a.out = out
+		assert.NoError(t, a.Run(context.Background()))
+		assert.Equal(t, filepath.Join(tempDir, "web")+"\n", out.String())
+
+		in, err := os.ReadFile(filepath.Join(binDir, "fzf.in"))
+		assert.NoError(t, err)
+
+		api := filepath.Join(tempDir, "api")
+		web := filepath.Join(tempDir, "web")
+		docs := filepath.Join(tempDir, "docs")
+
+		assert.Contains(t, string(in), api+"\t"+api+"  [main *]\n")
+		assert.Contains(t, string(in), web+"\t"+web+"  [main]\n")
+		assert.Contains(t, string(in), docs+"\t"+docs+"\n")
+	})
+
+	t.Run("Template", func(t *testing.T) {
+		a := newApp(config.Config{
+			Selector:       "fzf",
+			List:           true,
+			FormatTemplate: `{{.Name}}{{if .Git.Dirty}} dirty{{end}}{{if .Git.IsRepo}} {{.Git.CommitTime.UTC.Year}}{{end}}`,
+		})
+
+		out := new(bytes.Buffer)
+		a.out = out
+		assert.NoError(t, a.Run(context.Background()))
+		assert.Equal(t, filepath.Base(tempDir)+"\napi dirty 2024\ndocs\nweb 2024\n", out.String())
+	})
+}
+
+func TestGitAnnotation(t *testing.T) {
+	assert.Equal(t, "[main]", gitAnnotation(&git.Info{Branch: "main"}))
+	assert.Equal(t, "[feat * ↑2 ↓1]", gitAnnotation(&git.Info{Branch: "feat", Dirty: true, Ahead: 2, Behind: 1}))
+	assert.Equal(t, "[4b825dc]", gitAnnotation(&git.Info{Commit: "4b825dc642cb6eb9a060e54bf8d69288fbee4904"}))
+	assert.Equal(t, "[HEAD]", gitAnnotation(&git.Info{}))
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/git"
+	"github.com/gabefiori/gsp/internal/selector"
+)
+
+// parseDisplay parses the template of the labels displayed in the selector.
+// It is executed once, so errors are reported before the selector starts.
+func parseDisplay(s, home string) (*template.Template, error) {
+	tmpl, err := parseTemplate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := finder.Entry{Path: home, Source: home}
+	if err := tmpl.Execute(io.Discard, newTemplateEntry(sample, func(p string) string { return p })); err != nil {
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// label returns the text displayed for an entry: its path, annotated with its git state when read,
+// or the display template output.
+func (a *App) label(e finder.Entry) string {
+	if a.display == nil {
+		if a.annotate && e.Git != nil {
+			return e.Path + "  " + gitAnnotation(e.Git)
+		}
+
+		return e.Path
+	}
+
+	buf := new(strings.Builder)
+	if err := a.display.Execute(buf, newTemplateEntry(e, a.expandHome)); err != nil {
+		return e.Path
+	}
+
+	// Each entry must stay on a single line.
+	return strings.ReplaceAll(buf.String(), "\n", " ")
+}
+
+// line returns the line written to the selector for an entry.
+// When the selector supports labels, the path is kept in front of the label, so selections are unambiguous.
+func (a *App) line(e finder.Entry) string {
+	if a.display == nil && !a.annotate {
+		return e.Path
+	}
+
+	if a.labeled {
+		return e.Path + selector.LabelSep + a.label(e)
+	}
+
+	return a.label(e)
+}
+
+// gitAnnotation summarizes the state of a repository, e.g. "[main * ↑1 ↓2]".
+// Uncommitted changes are marked with "*", and detached heads show their commit.
+func gitAnnotation(info *git.Info) string {
+	var b strings.Builder
+
+	b.WriteByte('[')
+
+	switch {
+	case info.Branch != "":
+		b.WriteString(info.Branch)
+	case len(info.Commit) >= 7:
+		b.WriteString(info.Commit[:7])
+	default:
+		b.WriteString("HEAD")
+	}
+
+	if info.Dirty {
+		b.WriteString(" *")
+	}
+
+	if info.Ahead > 0 {
+		b.WriteString(" ↑" + strconv.Itoa(info.Ahead))
+	}
+
+	if info.Behind > 0 {
+		b.WriteString(" ↓" + strconv.Itoa(info.Behind))
+	}
+
+	b.WriteByte(']')
+
+	return b.String()
+}
@@ -0,0 +1,84 @@
+// Package cache persists the finder results between runs.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/mitchellh/go-homedir"
+)
+
+// Data is the content of a cache file.
+type Data struct {
+	// Results of the last run, in the order they were emitted.
+	Results []finder.Entry
+
+	// Listings of the directories walked by the last run.
+	Dirs map[string]finder.CachedDir
+}
+
+// Dir returns the directory of the cache files: "$XDG_CACHE_HOME/gsp", or "~/.cache/gsp".
+func Dir() (string, error) {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "gsp"), nil
+	}
+
+	return homedir.Expand("~/.cache/gsp")
+}
+
+// Key returns a file name identifying the configuration v.
+// Different configurations must not share the same cache.
+// v must only hold plain values: pointers and functions would make the key change between runs.
+func Key(v any) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%#v", v)))
+	return hex.EncodeToString(sum[:12])
+}
+
+// Load reads the cache file at path.
+// A missing file is reported with an error wrapping [os.ErrNotExist].
+func Load(path string) (*Data, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var d Data
+	if err := gob.NewDecoder(file).Decode(&d); err != nil {
+		return nil, fmt.Errorf("invalid cache %q: %w", path, err)
+	}
+
+	return &d, nil
+}
+
+// Save writes d to the cache file at path.
+// The file is replaced atomically, so concurrent runs never read a partial cache.
+func Save(path string, d *Data) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(d); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
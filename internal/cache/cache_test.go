@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gsp", Key("config"))
+
+	_, err := Load(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	data := &Data{
+		Results: []finder.Entry{{Path: "~/a", Source: "~", Depth: 1}, {Path: "~/b", Source: "~", Depth: 1, Symlink: true}},
+		Dirs: map[string]finder.CachedDir{
+			"/home/a": {
+				ModTime: 42,
+				Entries: []finder.CachedEntry{{EntryName: "b", EntryType: os.ModeDir}},
+			},
+		},
+	}
+
+	assert.NoError(t, Save(path, data))
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, data, loaded)
+}
+
+func TestKey(t *testing.T) {
+	type config struct{ Sort string }
+
+	assert.Equal(t, Key(config{"asc"}), Key(config{"asc"}))
+	assert.NotEqual(t, Key(config{"asc"}), Key(config{"desc"}))
+}
+
+func TestDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg")
+
+	dir, err := Dir()
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/xdg/gsp", dir)
+}
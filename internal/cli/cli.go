@@ -2,15 +2,30 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
 
 	"github.com/gabefiori/gsp/internal/app"
 	"github.com/gabefiori/gsp/internal/config"
+	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/preview"
+	"github.com/gabefiori/gsp/internal/run"
+	"github.com/gabefiori/gsp/internal/shellinit"
+	"github.com/gabefiori/gsp/internal/tmux"
+	"github.com/mitchellh/go-homedir"
 	"github.com/urfave/cli/v3"
 )
 
 // Run initializes and executes the command-line interface (CLI) application.
 func Run(version string) error {
+	// Command run by "gsp exec", see splitCommand.
+	var command []string
+
 	// flags
 	var (
 		flagConfig = &cli.StringFlag{
@@ -35,16 +50,75 @@ func Run(version string) error {
 			Value:   false,
 		}
 
+		flagFilter = &cli.StringFlag{
+			Name:    "filter",
+			Aliases: []string{"f"},
+			Usage:   "Print entries matching the fuzzy `query`, best matches first, without a selector",
+		}
+
+		flagFirst = &cli.BoolFlag{
+			Name:  "first",
+			Usage: "Print only the best entry (used with --filter)",
+			Value: false,
+		}
+
+		flagFormat = &cli.StringFlag{
+			Name:  "format",
+			Usage: "Output `format` of the entries (available options: 'text', 'json', 'ndjson')",
+			Value: "text",
+		}
+
+		flagFormatTemplate = &cli.StringFlag{
+			Name:  "format-template",
+			Usage: "Print each entry with a Go `template` (e.g. '{{.Name}}\\t{{.Path}}'). Fields: Name, Path, Display, Source, Rel, Depth, Symlink, Git.Branch, Git.Dirty",
+		}
+
+		flagPrint0 = &cli.BoolFlag{
+			Name:    "print0",
+			Aliases: []string{"0"},
+			Usage:   "Terminate printed entries with NUL instead of newline (e.g. for 'xargs -0')",
+			Value:   false,
+		}
+
+		flagMulti = &cli.BoolFlag{
+			Name:  "multi",
+			Usage: "Select several entries, printing one per line (not supported by 'fzy' and custom commands)",
+			Value: false,
+		}
+
+		flagAction = &cli.StringFlag{
+			Name:    "action",
+			Aliases: []string{"a"},
+			Usage:   "Run the configured action `name` on the selected entries instead of printing them",
+		}
+
+		flagStrict = &cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Fail on the first unreadable entry instead of printing warnings",
+			Value: false,
+		}
+
+		flagTimeout = &cli.DurationFlag{
+			Name:    "timeout",
+			Aliases: []string{"t"},
+			Usage:   "Stop walking sources after the given `duration` (e.g. '500ms', '2s'), keeping the entries found so far",
+		}
+
 		flagSelector = &cli.StringFlag{
 			Name:    "selector",
 			Aliases: []string{"sl"},
-			Usage:   "Selector for displaying entries (available options: 'fzf', 'fzy', 'sk')",
+			Usage:   "Selector for displaying entries (available options: 'fzf', 'fzy', 'sk', 'builtin')",
+		}
+
+		flagSelectorCmd = &cli.StringFlag{
+			Name:  "selector-cmd",
+			Usage: "Custom selector `command` reading entries from stdin (e.g. 'fzf --reverse', 'peco'). Takes precedence over --selector",
 		}
 
 		flagSort = &cli.StringFlag{
 			Name:    "sort",
 			Aliases: []string{"s"},
-			Usage:   "Specify the sort order for displaying entries (available options: 'asc', 'desc', 'nosort')",
+			Usage:   "Specify the sort order for displaying entries (available options: 'asc', 'desc', 'frecency', 'mtime', 'mtime-asc', 'commit', 'commit-asc', 'nosort')",
 			Value:   "nosort",
 		}
 
@@ -55,6 +129,18 @@ func Run(version string) error {
 			Value:   false,
 		}
 
+		flagCache = &cli.BoolFlag{
+			Name:  "cache",
+			Usage: "Display cached entries right away, refreshing the cache in the background",
+			Value: false,
+		}
+
+		flagGit = &cli.BoolFlag{
+			Name:  "git",
+			Usage: "Read the state of git repositories (branch, last commit, dirty status, ahead/behind), for the output and the selector",
+			Value: false,
+		}
+
 		flagExpand = &cli.BoolFlag{
 			Name:    "expand-output",
 			Aliases: []string{"eo"},
@@ -63,6 +149,38 @@ func Run(version string) error {
 		}
 	)
 
+	// loadConfig loads the configuration file, overridden by the flags.
+	// Flags are shared with the subcommands selecting projects.
+	loadConfig := func(c *cli.Command) (*config.Config, error) {
+		params := &config.LoadParams{
+			Path:           c.String(flagConfig.Name),
+			Measure:        c.Bool(flagMeasure.Name),
+			List:           c.Bool(flagList.Name),
+			Filter:         c.String(flagFilter.Name),
+			First:          c.Bool(flagFirst.Name),
+			Format:         c.String(flagFormat.Name),
+			FormatTemplate: c.String(flagFormatTemplate.Name),
+			Multi:          c.Bool(flagMulti.Name),
+			Print0:         c.Bool(flagPrint0.Name),
+			Strict:         c.Bool(flagStrict.Name),
+			Timeout:        c.Duration(flagTimeout.Name),
+			Selector:       c.String(flagSelector.Name),
+			SelectorCmd:    c.String(flagSelectorCmd.Name),
+			Action:         c.String(flagAction.Name),
+		}
+
+		if c.IsSet(flagSort.Name) {
+			params.Sort = c.String(flagSort.Name)
+		}
+
+		params.Unique = optionalBoolFlag(flagUnique, c)
+		params.Cache = optionalBoolFlag(flagCache, c)
+		params.Git = optionalBoolFlag(flagGit, c)
+		params.ExpandOutput = optionalBoolFlag(flagExpand, c)
+
+		return config.Load(params)
+	}
+
 	cmd := cli.Command{
 		Name:    "gsp",
 		Usage:   "Select projects.",
@@ -71,27 +189,166 @@ func Run(version string) error {
 			flagConfig,
 			flagList,
 			flagMeasure,
+			flagFilter,
+			flagFirst,
+			flagFormat,
+			flagFormatTemplate,
+			flagMulti,
+			flagPrint0,
+			flagAction,
+			flagStrict,
+			flagTimeout,
 			flagSelector,
+			flagSelectorCmd,
 			flagSort,
 			flagUnique,
+			flagCache,
+			flagGit,
 			flagExpand,
 		},
-		Action: func(ctx context.Context, c *cli.Command) error {
-			params := &config.LoadParams{
-				Path:     c.String(flagConfig.Name),
-				Measure:  c.Bool(flagMeasure.Name),
-				List:     c.Bool(flagList.Name),
-				Selector: c.String(flagSelector.Name),
-			}
+		Commands: []*cli.Command{
+			{
+				Name:      "preview",
+				Usage:     "Print a summary of a project (git status, languages and README)",
+				ArgsUsage: "<path>",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.NArg() != 1 {
+						return errors.New("expected a single path")
+					}
 
-			if c.IsSet(flagSort.Name) {
-				params.Sort = c.String(flagSort.Name)
-			}
+					dir, err := homedir.Expand(c.Args().First())
+					if err != nil {
+						return err
+					}
 
-			params.Unique = optionalBoolFlag(flagUnique, c)
-			params.ExpandOutput = optionalBoolFlag(flagExpand, c)
+					return preview.Write(ctx, os.Stdout, dir)
+				},
+			},
+			{
+				Name:  "tmux",
+				Usage: "Select a project and attach to its tmux session, creating it if needed",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:      "layout",
+						Usage:     "Apply the tmux commands of the `file` to new sessions, relative to the project (overrides 'tmux-layout')",
+						TakesFile: true,
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					cfg, err := loadConfig(c)
+					if err != nil {
+						return err
+					}
 
-			cfg, err := config.Load(params)
+					// Only one session can be attached to.
+					cfg.First = cfg.Filter != ""
+
+					a, err := app.New(cfg)
+					if err != nil {
+						return err
+					}
+
+					layout := cfg.TmuxLayout
+					if c.IsSet("layout") {
+						layout = c.String("layout")
+					}
+
+					a.OnSelect = func(ctx context.Context, entries []finder.Entry) error {
+						return tmux.Open(ctx, finder.Paths(entries), tmux.Options{Layout: layout})
+					}
+
+					return a.Run(ctx)
+				},
+			},
+			{
+				Name:      "exec",
+				Usage:     "Select projects and run a command in each of them, exiting with its exit code",
+				ArgsUsage: "-- <command> [args...]",
+				Action: func(ctx context.Context, c *cli.Command) error {
+					args := command
+					if len(args) == 0 {
+						args = c.Args().Slice()
+					}
+
+					if len(args) == 0 {
+						return errors.New("expected a command")
+					}
+
+					cfg, err := loadConfig(c)
+					if err != nil {
+						return err
+					}
+
+					// Fuzzy queries match loosely, so the command only runs in the best match.
+					cfg.First = cfg.Filter != ""
+
+					a, err := app.New(cfg)
+					if err != nil {
+						return err
+					}
+
+					a.OnSelect = func(ctx context.Context, entries []finder.Entry) error {
+						for _, e := range entries {
+							// Interrupted while running the command of a previous project.
+							if err := ctx.Err(); err != nil {
+								return err
+							}
+
+							if len(entries) > 1 {
+								fmt.Fprintf(os.Stderr, "==> %s\n", e.Path)
+							}
+
+							if err := run.Command(e.Path, args, os.Stdout); err != nil {
+								// The command already reported the failure.
+								if code, ok := run.ExitCode(err); ok {
+									return cli.Exit("", code)
+								}
+
+								return err
+							}
+						}
+
+						return nil
+					}
+
+					return a.Run(ctx)
+				},
+			},
+			{
+				Name:      "init",
+				Usage:     "Print a shell function changing to the selected project (e.g. 'eval \"$(gsp init bash)\"')",
+				ArgsUsage: "<bash|zsh|fish|nushell|powershell>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "Name of the shell `function`",
+						Value: "sp",
+					},
+					&cli.StringFlag{
+						Name:  "key",
+						Usage: "Bind the function to a `key` (e.g. 'ctrl-g', 'alt-p')",
+					},
+				},
+				Action: func(ctx context.Context, c *cli.Command) error {
+					if c.NArg() != 1 {
+						return errors.New("expected a single shell")
+					}
+
+					script, err := shellinit.Script(c.Args().First(), shellinit.Options{
+						Name: c.String("name"),
+						Key:  c.String("key"),
+					})
+					if err != nil {
+						return err
+					}
+
+					_, err = os.Stdout.WriteString(script)
+					return err
+				},
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			cfg, err := loadConfig(c)
 			if err != nil {
 				return err
 			}
@@ -101,11 +358,57 @@ func Run(version string) error {
 				return err
 			}
 
-			return a.Run()
+			return a.Run(ctx)
 		},
 	}
 
-	return cmd.Run(context.Background(), os.Args)
+	args, command := splitCommand(os.Args, cmd.Flags)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return cmd.Run(ctx, args)
+}
+
+// splitCommand splits the command of "gsp exec" from the arguments parsed by the cli library,
+// which keeps parsing flags after "--" in subcommands (e.g. "-c" in "gsp exec -- sh -c 'make'").
+// The global flags are needed to tell the subcommand from their values (e.g. "gsp --filter exec -- ls").
+func splitCommand(args []string, flags []cli.Flag) ([]string, []string) {
+	i := slices.Index(args, "--")
+	if i == -1 || subcommand(args[1:i], flags) != "exec" {
+		return args, nil
+	}
+
+	return args[:i], args[i+1:]
+}
+
+// subcommand returns the first argument that is neither a flag nor the value of one, if any.
+func subcommand(args []string, flags []cli.Flag) string {
+	takesValue := make(map[string]bool)
+	for _, f := range flags {
+		if _, ok := f.(*cli.BoolFlag); ok {
+			continue
+		}
+
+		for _, name := range f.Names() {
+			takesValue[name] = true
+		}
+	}
+
+	for i := 0; i < len(args); i++ {
+		name, isFlag := strings.CutPrefix(args[i], "-")
+		if !isFlag || name == "" {
+			return args[i]
+		}
+
+		// Values may also be given as "--flag=value".
+		name = strings.TrimPrefix(name, "-")
+		if !strings.Contains(name, "=") && takesValue[name] {
+			i++
+		}
+	}
+
+	return ""
 }
 
 func optionalBoolFlag(f *cli.BoolFlag, c *cli.Command) int8 {
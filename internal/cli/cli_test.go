@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+)
+
+func TestSplitCommand(t *testing.T) {
+	flags := []cli.Flag{
+		&cli.StringFlag{Name: "filter", Aliases: []string{"f"}},
+		&cli.BoolFlag{Name: "list", Aliases: []string{"l"}},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		command  []string
+	}{
+		{
+			name:     "Exec",
+			args:     []string{"gsp", "exec", "--filter", "api", "--", "sh", "-c", "make", "--", "x"},
+			expected: []string{"gsp", "exec", "--filter", "api"},
+			command:  []string{"sh", "-c", "make", "--", "x"},
+		},
+		{
+			name:     "Exec without separator",
+			args:     []string{"gsp", "exec", "make"},
+			expected: []string{"gsp", "exec", "make"},
+		},
+		{
+			name:     "Exec after flags",
+			args:     []string{"gsp", "-l", "-f", "api", "--filter=web", "exec", "--", "ls"},
+			expected: []string{"gsp", "-l", "-f", "api", "--filter=web", "exec"},
+			command:  []string{"ls"},
+		},
+		{
+			name:     "Other command",
+			args:     []string{"gsp", "--filter", "--", "exec"},
+			expected: []string{"gsp", "--filter", "--", "exec"},
+		},
+		{
+			name:     "Flag value",
+			args:     []string{"gsp", "--filter", "exec", "--", "ls"},
+			expected: []string{"gsp", "--filter", "exec", "--", "ls"},
+		},
+		{
+			name:     "Short flag value",
+			args:     []string{"gsp", "-f", "exec", "tmux", "--", "ls"},
+			expected: []string{"gsp", "-f", "exec", "tmux", "--", "ls"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, command := splitCommand(tt.args, flags)
+			assert.Equal(t, tt.expected, args)
+			assert.Equal(t, tt.command, command)
+		})
+	}
+}
@@ -2,9 +2,13 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/shellwords"
 	"github.com/mitchellh/go-homedir"
 )
 
@@ -23,24 +27,125 @@ type Config struct {
 	// Flag to list results
 	List bool
 
+	// Query to filter the results with, instead of using a selector
+	Filter string
+
+	// Flag to only print the best result of the filter
+	First bool
+
+	// Flag to select several entries at once
+	Multi bool
+
+	// Output format of the entries (text, json or ndjson)
+	Format string
+
+	// Go template used to print each entry
+	FormatTemplate string
+
+	// Flag to terminate printed entries with NUL instead of newline
+	Print0 bool
+
+	// Flag to fail on the first error found while walking sources
+	Strict bool
+
+	// Maximum duration for walking sources. Zero means no limit.
+	Timeout time.Duration
+
 	// Selector for displaying the projects
 	Selector string
 
+	// Arguments passed to the selector (e.g. "--height", "40%").
+	SelectorArgs []string
+
+	// Command line of a custom selector. Takes precedence over Selector.
+	SelectorCmd []string
+
+	// Flag to show a summary of the current project in the selector (fzf and sk only).
+	Preview bool
+
+	// Go template of the labels displayed in the selector, instead of the paths.
+	Display string
+
+	// Commands run on the selected projects instead of printing them.
+	Actions []Action
+
+	// Name of the action run on the selection, instead of printing it.
+	Action string
+
+	// Layout file applied to the tmux sessions created by "gsp tmux", relative to each project.
+	TmuxLayout string
+
 	// Flag to display only unique projects.
 	Unique bool
 
 	// Type of sorting.
 	Sort string
+
+	// Markers used to identify project roots.
+	// Applied to sources that do not define their own.
+	Markers []string
+
+	// Flag to stop descending once a project root is found.
+	// Applied to sources that do not define their own.
+	StopAtMarker bool
+
+	// Gitignore-style patterns of directories to skip.
+	// Prepended to the patterns of every source.
+	Exclude []string
+
+	// Flag to skip directories matched by ignore files (e.g. ".gitignore").
+	// Applied to sources that do not define their own.
+	Gitignore bool
+
+	// How hidden directories are handled.
+	// Applied to sources that do not define their own.
+	Hidden finder.HiddenMode
+
+	// How entries pointing to the same physical directory are handled.
+	Dedupe finder.DedupeMode
+
+	// Number of directories walked in parallel. Zero means the number of CPUs.
+	Threads int
+
+	// Flag to feed results from the cache, refreshing it in the background.
+	Cache bool
+
+	// Flag to read the state of git repositories (branch, last commit, dirty status, ahead/behind).
+	Git bool
+}
+
+// Action is a named command run on the selected projects.
+type Action struct {
+	Name string
+
+	// Command line, run in the project directory. "{path}" is replaced by the path of the project,
+	// and environment variables (e.g. "$SHELL") are expanded.
+	Command []string
+
+	// Key running the action from the selector (e.g. "ctrl-o"), instead of enter. Optional.
+	Key string
 }
 
 type LoadParams struct {
-	Selector     string
-	Sort         string
-	Path         string
-	ExpandOutput int8
-	Unique       int8
-	Measure      bool
-	List         bool
+	Selector       string
+	SelectorCmd    string
+	Action         string
+	Sort           string
+	Path           string
+	ExpandOutput   int8
+	Unique         int8
+	Cache          int8
+	Git            int8
+	Filter         string
+	Format         string
+	FormatTemplate string
+	Measure        bool
+	List           bool
+	First          bool
+	Multi          bool
+	Print0         bool
+	Strict         bool
+	Timeout        time.Duration
 }
 
 // Load reads the configuration from a JSON file at the specified path.
@@ -68,6 +173,17 @@ func Load(params *LoadParams) (*Config, error) {
 
 	cfg.Measure = params.Measure
 	cfg.List = params.List
+	cfg.Filter = params.Filter
+	cfg.First = params.First
+	cfg.Multi = params.Multi
+	cfg.Format = params.Format
+	cfg.FormatTemplate = params.FormatTemplate
+	cfg.Print0 = params.Print0
+	cfg.Strict = params.Strict
+
+	if params.Timeout != 0 {
+		cfg.Timeout = params.Timeout
+	}
 
 	if params.ExpandOutput != 0 {
 		cfg.ExpandOutput = params.ExpandOutput == 1
@@ -77,11 +193,32 @@ func Load(params *LoadParams) (*Config, error) {
 		cfg.Unique = params.Unique == 1
 	}
 
+	if params.Cache != 0 {
+		cfg.Cache = params.Cache == 1
+	}
+
+	if params.Git != 0 {
+		cfg.Git = params.Git == 1
+	}
+
+	// The configured arguments only apply to the configured selector, and the command replaces it.
 	if params.Selector != "" {
+		if !strings.EqualFold(params.Selector, cfg.Selector) {
+			cfg.SelectorArgs = nil
+		}
+
 		cfg.Selector = params.Selector
+		cfg.SelectorCmd = nil
+	}
+
+	if params.SelectorCmd != "" {
+		cfg.SelectorCmd, err = shellwords.Split(params.SelectorCmd)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector command: %w", err)
+		}
 	}
 
-	if cfg.Selector == "" {
+	if cfg.Selector == "" && len(cfg.SelectorCmd) == 0 {
 		return nil, errors.New("invalid selector")
 	}
 
@@ -89,5 +226,24 @@ func Load(params *LoadParams) (*Config, error) {
 		cfg.Sort = params.Sort
 	}
 
+	if params.Action != "" {
+		if cfg.FindAction(params.Action) == nil {
+			return nil, fmt.Errorf("unknown action %q", params.Action)
+		}
+
+		cfg.Action = params.Action
+	}
+
 	return &cfg, nil
 }
+
+// FindAction returns the action with the given name, or nil if there is none.
+func (c *Config) FindAction(name string) *Action {
+	for i := range c.Actions {
+		if c.Actions[i].Name == name {
+			return &c.Actions[i]
+		}
+	}
+
+	return nil
+}
@@ -19,8 +19,10 @@ func TestConfig_Load(t *testing.T) {
 		source = 2:~/test_2/test_2
 		expand-output = false
 		selector = test-selector
+		selector-args = --reverse
 		unique = false
 		sort = asc
+		action.open = code {path}
 	`
 
 	_, err = tempFile.WriteString(sampleConfig)
@@ -36,13 +38,19 @@ func TestConfig_Load(t *testing.T) {
 
 	t.Run("With parameters specified", func(t *testing.T) {
 		params := &LoadParams{
-			Selector:     "other",
-			Sort:         "asc",
-			Path:         tempFile.Name(),
-			ExpandOutput: 1,
-			Unique:       1,
-			Measure:      true,
-			List:         true,
+			Selector:       "other",
+			Sort:           "asc",
+			Path:           tempFile.Name(),
+			ExpandOutput:   1,
+			Unique:         1,
+			Cache:          1,
+			Measure:        true,
+			List:           true,
+			Strict:         true,
+			Filter:         "api",
+			First:          true,
+			Format:         "json",
+			FormatTemplate: "{{.Name}}",
 		}
 
 		cfg, err := Load(params)
@@ -51,8 +59,15 @@ func TestConfig_Load(t *testing.T) {
 		assert.Equal(t, true, cfg.ExpandOutput)
 		assert.Equal(t, true, cfg.Measure)
 		assert.Equal(t, true, cfg.List)
+		assert.Equal(t, true, cfg.Strict)
+		assert.Equal(t, "api", cfg.Filter)
+		assert.Equal(t, true, cfg.First)
+		assert.Equal(t, "json", cfg.Format)
+		assert.Equal(t, "{{.Name}}", cfg.FormatTemplate)
 		assert.Equal(t, params.Selector, cfg.Selector)
+		assert.Nil(t, cfg.SelectorArgs)
 		assert.Equal(t, true, cfg.Unique)
+		assert.Equal(t, true, cfg.Cache)
 		assert.Equal(t, "asc", cfg.Sort)
 		assert.Equal(t, sources, cfg.Sources)
 	})
@@ -68,11 +83,52 @@ func TestConfig_Load(t *testing.T) {
 		assert.Equal(t, false, cfg.ExpandOutput)
 		assert.Equal(t, false, cfg.Measure)
 		assert.Equal(t, false, cfg.List)
+		assert.Equal(t, false, cfg.Strict)
 		assert.Equal(t, "test-selector", cfg.Selector)
 		assert.Equal(t, false, cfg.Unique)
+		assert.Equal(t, false, cfg.Cache)
 		assert.Equal(t, "asc", cfg.Sort)
 		assert.Equal(t, sources, cfg.Sources)
 	})
+
+	t.Run("With configured selector", func(t *testing.T) {
+		cfg, err := Load(&LoadParams{Path: tempFile.Name(), Selector: "Test-Selector"})
+		assert.NoError(t, err)
+
+		// The arguments still apply to the same selector.
+		assert.Equal(t, []string{"--reverse"}, cfg.SelectorArgs)
+	})
+
+	t.Run("With selector command", func(t *testing.T) {
+		params := &LoadParams{
+			Path:        tempFile.Name(),
+			SelectorCmd: "gum filter --placeholder 'Pick a project'",
+		}
+
+		cfg, err := Load(params)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"gum", "filter", "--placeholder", "Pick a project"}, cfg.SelectorCmd)
+	})
+
+	t.Run("With action", func(t *testing.T) {
+		cfg, err := Load(&LoadParams{Path: tempFile.Name(), Action: "open"})
+		assert.NoError(t, err)
+		assert.Equal(t, "open", cfg.Action)
+
+		_, err = Load(&LoadParams{Path: tempFile.Name(), Action: "edit"})
+		assert.EqualError(t, err, `unknown action "edit"`)
+	})
+
+	t.Run("With invalid selector command", func(t *testing.T) {
+		params := &LoadParams{
+			Path:        tempFile.Name(),
+			SelectorCmd: "gum filter 'Pick",
+		}
+
+		_, err := Load(params)
+		assert.Error(t, err)
+	})
 }
 
 func BenchmarkConfig_Load(b *testing.B) {
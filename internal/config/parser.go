@@ -6,14 +6,30 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gabefiori/gsp/internal/finder"
+	"github.com/gabefiori/gsp/internal/shellwords"
+)
+
+// sourceField identifies a source setting that was explicitly set
+// with a "source.<field>" key and must not be overridden by the global value.
+type sourceField uint16
+
+const (
+	fieldMarkers sourceField = 1 << iota
+	fieldStopAtMarker
+	fieldGitignore
+	fieldHidden
 )
 
 type Parser struct {
 	line int
 	sc   *bufio.Scanner
 	cfg  *Config
+
+	// Fields explicitly set for each source, indexed like cfg.Sources.
+	sourceFields []sourceField
 }
 
 func NewParser(r io.Reader, cfg *Config) *Parser {
@@ -44,19 +60,95 @@ func (p *Parser) Run() error {
 		}
 	}
 
+	p.applySourceDefaults()
+
+	for _, a := range p.cfg.Actions {
+		if len(a.Command) == 0 {
+			return fmt.Errorf("failed to parse config: action %q has no command", a.Name)
+		}
+	}
+
 	return nil
 }
 
 func (p *Parser) field(k, v string) error {
+	if strings.HasPrefix(k, "source.") {
+		return p.sourceField(strings.TrimPrefix(k, "source."), v)
+	}
+
+	if strings.HasPrefix(k, "action.") {
+		return p.actionField(strings.TrimPrefix(k, "action."), v)
+	}
+
 	switch k {
 	case "selector":
 		p.cfg.Selector = v
+	case "selector-args":
+		args, err := shellwords.Split(v)
+		if err != nil {
+			return p.lineErr(err.Error())
+		}
+
+		p.cfg.SelectorArgs = args
+	case "selector-cmd":
+		args, err := shellwords.Split(v)
+		if err != nil {
+			return p.lineErr(err.Error())
+		}
+
+		p.cfg.SelectorCmd = args
 	case "sort":
 		p.cfg.Sort = v
 	case "expand-output":
 		p.cfg.ExpandOutput = v == "true"
 	case "unique":
 		p.cfg.Unique = v == "true"
+	case "cache":
+		p.cfg.Cache = v == "true"
+	case "git":
+		p.cfg.Git = v == "true"
+	case "preview":
+		p.cfg.Preview = v == "true"
+	case "display":
+		p.cfg.Display = v
+	case "tmux-layout":
+		p.cfg.TmuxLayout = v
+	case "markers":
+		p.cfg.Markers = splitList(v)
+	case "stop-at-marker":
+		p.cfg.StopAtMarker = v == "true"
+	case "exclude":
+		p.cfg.Exclude = append(p.cfg.Exclude, splitList(v)...)
+	case "gitignore":
+		p.cfg.Gitignore = v == "true"
+	case "threads":
+		threads, err := strconv.ParseUint(v, 10, 16)
+		if err != nil {
+			return p.lineErr(err.Error())
+		}
+
+		p.cfg.Threads = int(threads)
+	case "timeout":
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return p.lineErr(err.Error())
+		}
+
+		p.cfg.Timeout = d
+	case "hidden":
+		mode, err := finder.HiddenModeFromStr(v)
+		if err != nil {
+			return p.lineErr(err.Error())
+		}
+
+		p.cfg.Hidden = mode
+	case "dedupe":
+		mode, err := finder.DedupeModeFromStr(v)
+		if err != nil {
+			return p.lineErr(err.Error())
+		}
+
+		p.cfg.Dedupe = mode
 	case "source":
 		sep := strings.IndexByte(v, ':')
 		if sep == -1 {
@@ -73,11 +165,121 @@ func (p *Parser) field(k, v string) error {
 			Depth:        uint8(depth),
 			OriginalPath: path,
 		})
+
+		p.sourceFields = append(p.sourceFields, 0)
+	}
+
+	return nil
+}
+
+// sourceField sets a field of the most recently defined source.
+func (p *Parser) sourceField(k, v string) error {
+	last := len(p.cfg.Sources) - 1
+	if last == -1 {
+		return p.lineErr("source field defined before any source")
+	}
+
+	s := &p.cfg.Sources[last]
+
+	switch k {
+	case "markers":
+		s.Markers = splitList(v)
+		p.sourceFields[last] |= fieldMarkers
+	case "stop-at-marker":
+		s.StopAtMarker = v == "true"
+		p.sourceFields[last] |= fieldStopAtMarker
+	case "exclude":
+		s.Exclude = append(s.Exclude, splitList(v)...)
+	case "gitignore":
+		s.Gitignore = v == "true"
+		p.sourceFields[last] |= fieldGitignore
+	case "hidden":
+		mode, err := finder.HiddenModeFromStr(v)
+		if err != nil {
+			return p.lineErr(err.Error())
+		}
+
+		s.Hidden = mode
+		p.sourceFields[last] |= fieldHidden
+	default:
+		return p.lineErr("invalid source field")
 	}
 
 	return nil
 }
 
+// actionField sets the command of an action ("action.<name>"), or its key ("action.<name>.key").
+func (p *Parser) actionField(k, v string) error {
+	name, field, _ := strings.Cut(k, ".")
+	if name == "" {
+		return p.lineErr("invalid action name")
+	}
+
+	a := p.cfg.FindAction(name)
+	if a == nil {
+		p.cfg.Actions = append(p.cfg.Actions, Action{Name: name})
+		a = &p.cfg.Actions[len(p.cfg.Actions)-1]
+	}
+
+	switch field {
+	case "":
+		args, err := shellwords.Split(v)
+		if err != nil {
+			return p.lineErr(err.Error())
+		}
+
+		a.Command = args
+	case "key":
+		a.Key = v
+	default:
+		return p.lineErr("invalid action field")
+	}
+
+	return nil
+}
+
+// applySourceDefaults copies global settings into sources that did not set them.
+func (p *Parser) applySourceDefaults() {
+	for i := range p.cfg.Sources {
+		s := &p.cfg.Sources[i]
+		set := p.sourceFields[i]
+
+		if set&fieldMarkers == 0 {
+			s.Markers = p.cfg.Markers
+		}
+
+		if set&fieldStopAtMarker == 0 {
+			s.StopAtMarker = p.cfg.StopAtMarker
+		}
+
+		if set&fieldGitignore == 0 {
+			s.Gitignore = p.cfg.Gitignore
+		}
+
+		if set&fieldHidden == 0 {
+			s.Hidden = p.cfg.Hidden
+		}
+
+		// Global patterns come first, so sources can negate them.
+		if len(p.cfg.Exclude) > 0 {
+			s.Exclude = append(append([]string(nil), p.cfg.Exclude...), s.Exclude...)
+		}
+	}
+}
+
 func (p *Parser) lineErr(msg string) error {
 	return fmt.Errorf("failed to parse config %q on line %d.", msg, p.line)
 }
+
+// splitList splits a comma-separated value, ignoring empty items.
+func splitList(v string) []string {
+	var items []string
+
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
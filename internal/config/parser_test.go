@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gabefiori/gsp/internal/finder"
 	"github.com/stretchr/testify/assert"
@@ -37,6 +38,270 @@ func TestParser_Run(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "Markers",
+			input: `
+				markers = .git, go.mod
+				stop-at-marker = true
+				source = 1:~/test_1/test_1
+				source = 2:~/test_2/test_2
+				source.markers = package.json
+				source.stop-at-marker = false
+			`,
+			expected: &Config{
+				Sources: []finder.Source{
+					{
+						OriginalPath: "~/test_1/test_1",
+						Depth:        1,
+						Markers:      []string{".git", "go.mod"},
+						StopAtMarker: true,
+					},
+					{
+						OriginalPath: "~/test_2/test_2",
+						Depth:        2,
+						Markers:      []string{"package.json"},
+						StopAtMarker: false,
+					},
+				},
+				Markers:      []string{".git", "go.mod"},
+				StopAtMarker: true,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Exclude",
+			input: `
+				exclude = node_modules, vendor/
+				exclude = .cache
+				source = 1:~/test_1/test_1
+				source = 2:~/test_2/test_2
+				source.exclude = !vendor/
+			`,
+			expected: &Config{
+				Sources: []finder.Source{
+					{
+						OriginalPath: "~/test_1/test_1",
+						Depth:        1,
+						Exclude:      []string{"node_modules", "vendor/", ".cache"},
+					},
+					{
+						OriginalPath: "~/test_2/test_2",
+						Depth:        2,
+						Exclude:      []string{"node_modules", "vendor/", ".cache", "!vendor/"},
+					},
+				},
+				Exclude: []string{"node_modules", "vendor/", ".cache"},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Gitignore",
+			input: `
+				gitignore = true
+				source = 1:~/test_1/test_1
+				source = 2:~/test_2/test_2
+				source.gitignore = false
+			`,
+			expected: &Config{
+				Sources: []finder.Source{
+					{OriginalPath: "~/test_1/test_1", Depth: 1, Gitignore: true},
+					{OriginalPath: "~/test_2/test_2", Depth: 2, Gitignore: false},
+				},
+				Gitignore: true,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Hidden",
+			input: `
+				hidden = skip
+				source = 1:~/test_1/test_1
+				source = 2:~/test_2/test_2
+				source.hidden = no-descend
+			`,
+			expected: &Config{
+				Sources: []finder.Source{
+					{OriginalPath: "~/test_1/test_1", Depth: 1, Hidden: finder.HiddenSkip},
+					{OriginalPath: "~/test_2/test_2", Depth: 2, Hidden: finder.HiddenNoDescend},
+				},
+				Hidden: finder.HiddenSkip,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Invalid hidden mode",
+			input: `
+				hidden = sometimes
+			`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name: "Timeout",
+			input: `
+				timeout = 1500ms
+			`,
+			expected: &Config{
+				Timeout: 1500 * time.Millisecond,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Invalid timeout",
+			input: `
+				timeout = soon
+			`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name: "Selector arguments",
+			input: `
+				selector = fzf
+				selector-args = --height 40% --prompt 'project> '
+			`,
+			expected: &Config{
+				Selector:     "fzf",
+				SelectorArgs: []string{"--height", "40%", "--prompt", "project> "},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Selector command",
+			input: `
+				selector-cmd = fzf --reverse --preview 'ls {}'
+			`,
+			expected: &Config{
+				SelectorCmd: []string{"fzf", "--reverse", "--preview", "ls {}"},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Preview",
+			input: `
+				selector = sk
+				preview = true
+			`,
+			expected: &Config{
+				Selector: "sk",
+				Preview:  true,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Display",
+			input: `
+				display = {{printf "%-20s" .Name}} {{.Display}}
+			`,
+			expected: &Config{
+				Display: `{{printf "%-20s" .Name}} {{.Display}}`,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Actions",
+			input: `
+				action.open = code {path}
+				action.open.key = ctrl-o
+				action.shell.key = alt-s
+				action.shell = $SHELL
+			`,
+			expected: &Config{
+				Actions: []Action{
+					{Name: "open", Command: []string{"code", "{path}"}, Key: "ctrl-o"},
+					{Name: "shell", Command: []string{"$SHELL"}, Key: "alt-s"},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Action without command",
+			input: `
+				action.open.key = ctrl-o
+			`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name: "Invalid action field",
+			input: `
+				action.open.keys = ctrl-o
+			`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name: "Git",
+			input: `
+				git = true
+			`,
+			expected: &Config{
+				Git: true,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Tmux layout",
+			input: `
+				tmux-layout = .tmux-layout
+			`,
+			expected: &Config{
+				TmuxLayout: ".tmux-layout",
+			},
+			expectErr: false,
+		},
+		{
+			name: "Invalid selector command",
+			input: `
+				selector-cmd = fzf --preview 'ls {}
+			`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name: "Dedupe",
+			input: `
+				dedupe = realpath
+			`,
+			expected: &Config{
+				Dedupe: finder.DedupeRealpath,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Invalid dedupe mode",
+			input: `
+				dedupe = inode
+			`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name: "Threads",
+			input: `
+				threads = 8
+			`,
+			expected: &Config{
+				Threads: 8,
+			},
+			expectErr: false,
+		},
+		{
+			name: "Invalid threads",
+			input: `
+				threads = -1
+			`,
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			name: "Source field without source",
+			input: `
+				source.markers = .git
+			`,
+			expected:  nil,
+			expectErr: true,
+		},
 		{
 			name: "Invalid source format",
 			input: `
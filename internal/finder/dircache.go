@@ -0,0 +1,138 @@
+package finder
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DirCache keeps the listings of the walked directories, so unchanged ones are not read again.
+// A listing is reused as long as the modification time of its directory does not change.
+//
+// Only the listings used by the current run are kept, see [DirCache.Dirs].
+type DirCache struct {
+	// Listings saved by a previous run.
+	prev map[string]CachedDir
+
+	mu   sync.Mutex
+	next map[string]CachedDir
+
+	reads atomic.Int64
+}
+
+// CachedDir is the listing of a directory.
+type CachedDir struct {
+	// Modification time in nanoseconds.
+	ModTime int64
+	Entries []CachedEntry
+}
+
+// CachedEntry is a directory entry of a [CachedDir]. It implements [fs.DirEntry].
+type CachedEntry struct {
+	EntryName string
+	EntryType fs.FileMode
+
+	// Directory containing the entry, used by [CachedEntry.Info].
+	dir string
+}
+
+func (e CachedEntry) Name() string               { return e.EntryName }
+func (e CachedEntry) IsDir() bool                { return e.EntryType.IsDir() }
+func (e CachedEntry) Type() fs.FileMode          { return e.EntryType }
+func (e CachedEntry) Info() (fs.FileInfo, error) { return os.Lstat(filepath.Join(e.dir, e.EntryName)) }
+
+// NewDirCache creates a cache from the listings saved by a previous run, which may be nil.
+func NewDirCache(prev map[string]CachedDir) *DirCache {
+	return &DirCache{
+		prev: prev,
+		next: make(map[string]CachedDir, len(prev)),
+	}
+}
+
+// Dirs returns the listings used by the current run, to be saved for the next one.
+// It must only be called once the run is done.
+func (c *DirCache) Dirs() map[string]CachedDir {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.next
+}
+
+// Reads returns how many directories were read from disk, instead of the cache.
+func (c *DirCache) Reads() int64 {
+	return c.reads.Load()
+}
+
+func (c *DirCache) lookup(name string, modTime int64) ([]os.DirEntry, bool) {
+	d, ok := c.prev[name]
+	if !ok || d.ModTime != modTime {
+		return nil, false
+	}
+
+	entries := make([]os.DirEntry, len(d.Entries))
+	for i, e := range d.Entries {
+		e.dir = name
+		entries[i] = e
+	}
+
+	c.store(name, d)
+	return entries, true
+}
+
+func (c *DirCache) store(name string, d CachedDir) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.next[name] = d
+}
+
+// readDir reads the directory name, returning its identifier and its entries sorted by name.
+// The identifier is only valid if ok is set. When c is not nil, unchanged listings are taken from it.
+func readDir(name string, c *DirCache) (id fileID, ok bool, entries []os.DirEntry, err error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return fileID{}, false, nil, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fileID{}, false, nil, err
+	}
+
+	id, ok = fileIDOf(info)
+	modTime := info.ModTime().UnixNano()
+
+	if c != nil {
+		if entries, hit := c.lookup(name, modTime); hit {
+			return id, ok, entries, nil
+		}
+
+		c.reads.Add(1)
+	}
+
+	entries, err = f.ReadDir(-1)
+	if err != nil {
+		return fileID{}, false, nil, err
+	}
+
+	slices.SortFunc(entries, func(a, b os.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	if c != nil {
+		d := CachedDir{ModTime: modTime, Entries: make([]CachedEntry, len(entries))}
+		for i, e := range entries {
+			d.Entries[i] = CachedEntry{EntryName: e.Name(), EntryType: e.Type()}
+		}
+
+		c.store(name, d)
+	}
+
+	return id, ok, entries, nil
+}
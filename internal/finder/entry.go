@@ -0,0 +1,41 @@
+package finder
+
+import (
+	"time"
+
+	"github.com/gabefiori/gsp/internal/git"
+)
+
+// Entry is a directory found by the finder.
+type Entry struct {
+	// Path of the directory. The home directory is replaced by "~" when the source path starts with it.
+	Path string
+
+	// Path of the source the directory was found in, as configured.
+	Source string
+
+	// Depth of the directory within its source. The source path itself is at depth 0.
+	Depth uint8
+
+	// Whether the directory is a symbolic link.
+	Symlink bool
+
+	// State of the repository, when the directory is one and git information was requested.
+	Git *git.Info
+
+	// Modification time of the directory, only read for [MTimeSort] and [MTimeAscSort].
+	ModTime time.Time
+
+	// Time of the HEAD commit, only read for [CommitSort] and [CommitAscSort] when the directory is a repository.
+	CommitTime time.Time
+}
+
+// Paths returns the paths of the entries.
+func Paths(entries []Entry) []string {
+	paths := make([]string, len(entries))
+	for i, e := range entries {
+		paths[i] = e.Path
+	}
+
+	return paths
+}
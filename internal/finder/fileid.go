@@ -0,0 +1,92 @@
+package finder
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// DedupeMode defines how entries pointing to the same directory are handled.
+type DedupeMode int8
+
+const (
+	// Keep every entry, as long as it is not part of a symlink cycle.
+	DedupeNone DedupeMode = iota
+
+	// Keep only the first entry resolving to each physical directory.
+	DedupeRealpath
+)
+
+func DedupeModeFromStr(s string) (DedupeMode, error) {
+	switch strings.ToLower(s) {
+	case "none":
+		return DedupeNone, nil
+	case "realpath":
+		return DedupeRealpath, nil
+	default:
+		return DedupeNone, fmt.Errorf("invalid dedupe mode '%s'", s)
+	}
+}
+
+// fileID identifies a physical directory by its device and inode numbers.
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// fileIDSet is a set of directories shared by the sources of a run.
+type fileIDSet struct {
+	mu  sync.Mutex
+	ids map[fileID]visitedDir
+}
+
+// visitedDir is the state of a directory found by one or more paths.
+type visitedDir struct {
+	// Largest number of levels below the directory that were walked.
+	remaining int
+
+	// Whether the directory was emitted as an entry.
+	emitted bool
+}
+
+func newFileIDSet() *fileIDSet {
+	return &fileIDSet{ids: make(map[fileID]visitedDir)}
+}
+
+// visit records a visit of id with the given number of levels left to walk below it,
+// and whether it is a project by that path.
+// It reports whether the directory must be emitted, which only happens once,
+// and whether it must be descended: the first time, or when there are more levels left
+// than in previous visits, so a directory first found at its depth limit does not hide its subtree.
+func (s *fileIDSet) visit(id fileID, remaining int, project bool) (emit, descend bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	v, exists := s.ids[id]
+
+	emit = project && !v.emitted
+	descend = !exists || remaining > v.remaining
+
+	if emit {
+		v.emitted = true
+	}
+
+	if descend {
+		v.remaining = remaining
+	}
+
+	s.ids[id] = v
+	return emit, descend
+}
+
+// statID returns the identifier of the file name, following symlinks.
+func statID(name string) (fileID, bool, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileID{}, false, err
+	}
+
+	id, ok := fileIDOf(info)
+	return id, ok, nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package finder
+
+import "os"
+
+// Device and inode numbers are not available, so cycles and duplicates are not detected.
+func fileIDOf(info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
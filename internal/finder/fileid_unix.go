@@ -0,0 +1,17 @@
+//go:build unix
+
+package finder
+
+import (
+	"os"
+	"syscall"
+)
+
+func fileIDOf(info os.FileInfo) (fileID, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileID{}, false
+	}
+
+	return fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}, true
+}
@@ -1,57 +1,113 @@
 package finder
 
 import (
-	"log"
+	"context"
+	"errors"
+	"runtime"
 	"strings"
-	"sync"
+	"time"
 )
 
 type FinderOpts struct {
 	Sources  []Source
 	HomeDir  string
-	ResultCh chan string
+	ResultCh chan Entry
+
+	// Channel to receive errors from the sources, closed once every source is done.
+	// When nil, errors are discarded.
+	ErrCh chan error
+
 	SortType SortType
 	Unique   bool
+
+	// Scores used by [FrecencySort].
+	Score ScoreFunc
+
+	// How entries pointing to the same physical directory are handled.
+	Dedupe DedupeMode
+
+	// Stop walking a source at its first error, instead of skipping unreadable entries.
+	Strict bool
+
+	// Number of directories walked in parallel, across all sources.
+	// Defaults to the number of CPUs.
+	Threads int
+
+	// Cache of directory listings, so only changed directories are read. Optional.
+	DirCache *DirCache
+
+	// Time at which the sources stop walking. Unlike ctx, it keeps the entries found so far:
+	// they are still sorted and sent. Optional.
+	Deadline time.Time
 }
 
 // Run executes the package finder using the provided options.
-// Any error encountered within this function is considered fatal and will terminate the program.
+// Errors never stop other sources; they are sent to [FinderOpts.ErrCh].
+// When ctx is done, every source stops walking and the channels are closed.
 //
-// Each source runs its [Find] method in a separate goroutine.
-func Run(opts *FinderOpts) {
-	var wg sync.WaitGroup
-	var pipeCh chan string
+// Directories of every source are walked by a bounded pool of [FinderOpts.Threads] workers.
+func Run(ctx context.Context, opts *FinderOpts) {
+	walkCtx := ctx
+	if !opts.Deadline.IsZero() {
+		var cancel context.CancelFunc
+
+		walkCtx, cancel = context.WithDeadline(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	var pipeCh chan Entry
 
 	ch := opts.ResultCh
 	usePipe := opts.SortType != NoSort || opts.Unique
 
 	if usePipe {
-		pipeCh = make(chan string, cap(opts.ResultCh))
+		pipeCh = make(chan Entry, cap(opts.ResultCh))
 		ch = pipeCh
 	}
 
-	for _, source := range opts.Sources {
-		wg.Add(1)
+	// A nil channel makes the sources fail fast.
+	var walkErrCh chan<- error
+	if !opts.Strict && opts.ErrCh != nil {
+		walkErrCh = opts.ErrCh
+	}
+
+	var seen *fileIDSet
+	if opts.Dedupe == DedupeRealpath {
+		seen = newFileIDSet()
+	}
 
-		go func() {
-			defer wg.Done()
+	threads := opts.Threads
+	if threads <= 0 {
+		threads = runtime.GOMAXPROCS(0)
+	}
 
-			err := source.Find(ch, func(s string) string {
-				if strings.HasPrefix(source.OriginalPath, "~") {
-					return "~" + strings.TrimPrefix(s, opts.HomeDir)
-				}
+	w := newWalker(threads, func(_ *Source, err error) {
+		sendErr(ctx, opts, err)
+	})
 
-				return s
-			})
+	for _, source := range opts.Sources {
+		source.seen = seen
+		source.dirCache = opts.DirCache
 
-			if err != nil {
-				log.Fatal(err)
+		err := source.prepare(walkCtx, ch, walkErrCh, func(s string) string {
+			if strings.HasPrefix(source.OriginalPath, "~") {
+				return "~" + strings.TrimPrefix(s, opts.HomeDir)
 			}
-		}()
+
+			return s
+		})
+
+		if err != nil {
+			sendErr(ctx, opts, err)
+			continue
+		}
+
+		w.push(source.rootDir())
 	}
 
 	if !usePipe {
-		wg.Wait()
+		w.run()
+		closeErrCh(opts)
 		close(opts.ResultCh)
 
 		return
@@ -61,29 +117,57 @@ func Run(opts *FinderOpts) {
 		defer close(opts.ResultCh)
 
 		unique := make(map[string]struct{})
-		results := make([]string, 0, 50)
+		results := make([]Entry, 0, 50)
 
 		for r := range pipeCh {
 			if opts.Unique {
-				if _, exists := unique[r]; exists {
+				if _, exists := unique[r.Path]; exists {
 					continue
 				}
 
-				unique[r] = struct{}{}
+				unique[r.Path] = struct{}{}
 			}
 
 			results = append(results, r)
 		}
 
 		if opts.SortType != NoSort {
-			sortResults(results, opts.SortType)
+			ReadTimes(results, opts.SortType, opts.HomeDir, threads)
+			Sort(results, opts.SortType, opts.Score)
 		}
 
 		for _, r := range results {
-			opts.ResultCh <- r
+			select {
+			case opts.ResultCh <- r:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
-	wg.Wait()
+	w.run()
+	closeErrCh(opts)
 	close(pipeCh)
 }
+
+// sendErr sends an error that stopped a source to the error channel, if any.
+func sendErr(ctx context.Context, opts *FinderOpts, err error) {
+	if opts.ErrCh == nil || isContextErr(err) {
+		return
+	}
+
+	select {
+	case opts.ErrCh <- err:
+	case <-ctx.Done():
+	}
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func closeErrCh(opts *FinderOpts) {
+	if opts.ErrCh != nil {
+		close(opts.ErrCh)
+	}
+}
@@ -1,14 +1,230 @@
 package finder
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestRunCancel(t *testing.T) {
+	baseDir := t.TempDir()
+
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, os.Mkdir(filepath.Join(baseDir, fmt.Sprintf("dir-%d", i)), 0755))
+	}
+
+	for _, sortType := range []SortType{NoSort, AscSort} {
+		t.Run(fmt.Sprintf("SortType %d", sortType), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			resultCh := make(chan Entry)
+			errCh := make(chan error)
+
+			go Run(ctx, &FinderOpts{
+				Sources:  []Source{{OriginalPath: baseDir, Depth: 1}},
+				ResultCh: resultCh,
+				ErrCh:    errCh,
+				SortType: sortType,
+			})
+
+			<-resultCh
+			cancel()
+
+			// Without a reader, the walk would block forever on the remaining entries.
+			select {
+			case <-errCh:
+			case <-time.After(5 * time.Second):
+				t.Fatal("finder did not stop after cancellation")
+			}
+		})
+	}
+}
+
+func TestRunDeadline(t *testing.T) {
+	baseDir := t.TempDir()
+
+	assert.NoError(t, os.Mkdir(filepath.Join(baseDir, "project"), 0755))
+	assert.NoError(t, os.Symlink(filepath.Join(baseDir, "missing"), filepath.Join(baseDir, "broken")))
+
+	resultCh := make(chan Entry)
+
+	// The broken symlink is reported to an error channel no one reads,
+	// so the walk is stuck after emitting the source path until the deadline.
+	go Run(context.Background(), &FinderOpts{
+		Sources:  []Source{{OriginalPath: baseDir, Depth: 1}},
+		ResultCh: resultCh,
+		ErrCh:    make(chan error),
+		SortType: AscSort,
+		Unique:   true,
+		Threads:  1,
+		Deadline: time.Now().Add(50 * time.Millisecond),
+	})
+
+	var paths []string
+	for r := range resultCh {
+		paths = append(paths, r.Path)
+	}
+
+	// The entries found before the deadline are kept.
+	assert.Equal(t, []string{baseDir}, paths)
+}
+
+func TestRunThreads(t *testing.T) {
+	baseDir := t.TempDir()
+
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			assert.NoError(t, os.MkdirAll(filepath.Join(baseDir, fmt.Sprintf("dir-%d", i), fmt.Sprintf("dir-%d", j)), 0755))
+		}
+	}
+
+	run := func(threads int) []string {
+		resultCh := make(chan Entry)
+
+		go Run(context.Background(), &FinderOpts{
+			Sources: []Source{
+				{OriginalPath: baseDir, Depth: 2},
+				{OriginalPath: filepath.Join(baseDir, "dir-0"), Depth: 1},
+			},
+			ResultCh: resultCh,
+			Threads:  threads,
+		})
+
+		var paths []string
+		for r := range resultCh {
+			paths = append(paths, r.Path)
+		}
+
+		return paths
+	}
+
+	serial := run(1)
+	assert.Len(t, serial, 17)
+	assert.ElementsMatch(t, serial, run(4))
+}
+
+func TestRunDedupe(t *testing.T) {
+	baseDir := t.TempDir()
+
+	projectDir := filepath.Join(baseDir, "src", "project")
+	linkDir := filepath.Join(baseDir, "links")
+	linkedProject := filepath.Join(linkDir, "project")
+
+	assert.NoError(t, os.MkdirAll(projectDir, 0755))
+	assert.NoError(t, os.Mkdir(linkDir, 0755))
+	assert.NoError(t, os.Symlink(projectDir, linkedProject))
+
+	tests := []struct {
+		name     string
+		dedupe   DedupeMode
+		expected int
+	}{
+		{"None", DedupeNone, 2},
+		{"Realpath", DedupeRealpath, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resultCh := make(chan Entry)
+
+			go Run(context.Background(), &FinderOpts{
+				Sources: []Source{
+					{OriginalPath: filepath.Join(baseDir, "src"), Depth: 1},
+					{OriginalPath: linkDir, Depth: 1},
+				},
+				ResultCh: resultCh,
+				Dedupe:   tt.dedupe,
+			})
+
+			var projects int
+			for r := range resultCh {
+				if filepath.Base(r.Path) == "project" {
+					projects++
+				}
+			}
+
+			assert.Equal(t, tt.expected, projects)
+		})
+	}
+}
+
+func TestRunDedupeDepth(t *testing.T) {
+	baseDir := t.TempDir()
+	treeDir := filepath.Join(baseDir, "tree")
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(treeDir, "x", "y", "z"), 0755))
+
+	// The source of baseDir reaches "x" at its depth limit, and is walked first with a single thread,
+	// since the last pushed directory is visited first. The source of treeDir walks the whole tree.
+	for _, threads := range []int{1, 4} {
+		for range 10 {
+			resultCh := make(chan Entry)
+
+			go Run(context.Background(), &FinderOpts{
+				Sources: []Source{
+					{OriginalPath: treeDir, Depth: 3},
+					{OriginalPath: baseDir, Depth: 2},
+				},
+				ResultCh: resultCh,
+				Dedupe:   DedupeRealpath,
+				Threads:  threads,
+			})
+
+			var names []string
+			for r := range resultCh {
+				names = append(names, filepath.Base(r.Path))
+			}
+
+			assert.ElementsMatch(t, []string{filepath.Base(baseDir), "tree", "x", "y", "z"}, names)
+		}
+	}
+}
+
+func TestRunDirCache(t *testing.T) {
+	baseDir := t.TempDir()
+
+	for _, name := range []string{"a", "b"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(baseDir, name, "nested"), 0755))
+	}
+
+	run := func(dirCache *DirCache) []string {
+		resultCh := make(chan Entry)
+
+		go Run(context.Background(), &FinderOpts{
+			Sources:  []Source{{OriginalPath: baseDir, Depth: 3}},
+			ResultCh: resultCh,
+			DirCache: dirCache,
+		})
+
+		var paths []string
+		for r := range resultCh {
+			paths = append(paths, r.Path)
+		}
+
+		return paths
+	}
+
+	first := NewDirCache(nil)
+	assert.Len(t, run(first), 5)
+	assert.Equal(t, int64(5), first.Reads())
+
+	second := NewDirCache(first.Dirs())
+	assert.Len(t, run(second), 5)
+	assert.Equal(t, int64(0), second.Reads())
+
+	// Only the modified directory is read again.
+	assert.NoError(t, os.Mkdir(filepath.Join(baseDir, "b", "new"), 0755))
+
+	third := NewDirCache(second.Dirs())
+	assert.Contains(t, run(third), filepath.Join(baseDir, "b", "new"))
+	assert.Equal(t, int64(2), third.Reads())
+}
+
 func BenchmarkRun(b *testing.B) {
 	tempDir := b.TempDir()
 	baseDir := filepath.Join(tempDir, "base")
@@ -33,7 +249,7 @@ func BenchmarkRun(b *testing.B) {
 
 		for _, tt := range tests {
 			b.Run(fmt.Sprintf("Depth_%d/%s", depth, tt.name), func(b *testing.B) {
-				resultCh := make(chan string, 3)
+				resultCh := make(chan Entry, 3)
 				opts := &FinderOpts{
 					Sources:  []Source{source, source, source},
 					HomeDir:  baseDir,
@@ -44,13 +260,13 @@ func BenchmarkRun(b *testing.B) {
 
 				b.ResetTimer()
 				for i := 0; i < b.N; i++ {
-					go Run(opts)
+					go Run(context.Background(), opts)
 
 					for range resultCh {
 					}
 
 					//FIXME: this affects the benchmark.
-					resultCh = make(chan string, 3)
+					resultCh = make(chan Entry, 3)
 					opts.ResultCh = resultCh
 				}
 			})
@@ -60,6 +276,38 @@ func BenchmarkRun(b *testing.B) {
 	assert.NoError(b, os.RemoveAll(baseDir))
 }
 
+// BenchmarkRunThreads compares walking a single large source serially,
+// as each source was walked before the worker pool, with walking it in parallel.
+func BenchmarkRunThreads(b *testing.B) {
+	baseDir := filepath.Join(b.TempDir(), "base")
+	createNestedDirs(b, baseDir, 0, 4)
+
+	source := Source{OriginalPath: baseDir, Depth: 5}
+
+	threads := []int{1, 2, 4}
+	if n := runtime.GOMAXPROCS(0); n > 4 {
+		threads = append(threads, n)
+	}
+
+	for _, threads := range threads {
+		b.Run(fmt.Sprintf("Threads_%d", threads), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				resultCh := make(chan Entry, 50)
+
+				go Run(context.Background(), &FinderOpts{
+					Sources:  []Source{source},
+					HomeDir:  baseDir,
+					ResultCh: resultCh,
+					Threads:  threads,
+				})
+
+				for range resultCh {
+				}
+			}
+		})
+	}
+}
+
 func createNestedDirs(b *testing.B, baseDir string, currentDepth, maxDepth int) {
 	if currentDepth > maxDepth {
 		return
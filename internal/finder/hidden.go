@@ -0,0 +1,37 @@
+package finder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HiddenMode defines how a source handles hidden directories (names starting with a dot).
+type HiddenMode int8
+
+const (
+	// Emit and walk hidden directories like any other.
+	HiddenInclude HiddenMode = iota
+
+	// Neither emit nor walk hidden directories.
+	HiddenSkip
+
+	// Emit hidden directories, but do not walk them.
+	HiddenNoDescend
+)
+
+func HiddenModeFromStr(s string) (HiddenMode, error) {
+	switch strings.ToLower(s) {
+	case "include":
+		return HiddenInclude, nil
+	case "skip":
+		return HiddenSkip, nil
+	case "no-descend":
+		return HiddenNoDescend, nil
+	default:
+		return HiddenInclude, fmt.Errorf("invalid hidden mode '%s'", s)
+	}
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
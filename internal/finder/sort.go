@@ -1,8 +1,16 @@
 package finder
 
 import (
-	"sort"
+	"cmp"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/gabefiori/gsp/internal/git"
 )
 
 type SortType int8
@@ -11,6 +19,18 @@ const (
 	NoSort SortType = iota
 	AscSort
 	DescSort
+
+	// Highest scores first (see [FinderOpts.Score]), then ascending.
+	FrecencySort
+
+	// Most recently modified directories first (see [Entry.ModTime]), then ascending.
+	MTimeSort
+	MTimeAscSort
+
+	// Most recent HEAD commits first (see [Entry.CommitTime]), then ascending.
+	// Directories that are not repositories come last.
+	CommitSort
+	CommitAscSort
 )
 
 func SortTypeFromStr(s string) SortType {
@@ -19,16 +39,135 @@ func SortTypeFromStr(s string) SortType {
 		return AscSort
 	case "desc":
 		return DescSort
+	case "frecency":
+		return FrecencySort
+	case "mtime":
+		return MTimeSort
+	case "mtime-asc":
+		return MTimeAscSort
+	case "commit":
+		return CommitSort
+	case "commit-asc":
+		return CommitAscSort
 	default:
 		return NoSort
 	}
 }
 
-func sortResults(r []string, t SortType) {
+// ScoreFunc returns the score of a result path for [FrecencySort].
+type ScoreFunc func(path string) float64
+
+// Sort sorts the results in place by path. The score function is only used by [FrecencySort].
+// Time sorts use the times already read into the entries (see [ReadTimes]).
+func Sort(r []Entry, t SortType, score ScoreFunc) {
 	switch t {
 	case AscSort:
-		sort.Strings(r)
+		slices.SortStableFunc(r, func(a, b Entry) int {
+			return strings.Compare(a.Path, b.Path)
+		})
 	case DescSort:
-		sort.Sort(sort.Reverse(sort.StringSlice(r)))
+		slices.SortStableFunc(r, func(a, b Entry) int {
+			return strings.Compare(b.Path, a.Path)
+		})
+	case FrecencySort:
+		scores := make(map[string]float64, len(r))
+		for _, e := range r {
+			if score != nil {
+				scores[e.Path] = score(e.Path)
+			}
+		}
+
+		slices.SortStableFunc(r, func(a, b Entry) int {
+			if c := cmp.Compare(scores[b.Path], scores[a.Path]); c != 0 {
+				return c
+			}
+
+			return strings.Compare(a.Path, b.Path)
+		})
+	case MTimeSort, MTimeAscSort:
+		sortByTime(r, t == MTimeAscSort, func(e *Entry) time.Time { return e.ModTime })
+	case CommitSort, CommitAscSort:
+		sortByTime(r, t == CommitAscSort, func(e *Entry) time.Time { return e.CommitTime })
 	}
 }
+
+// sortByTime sorts the results by the time returned by fn, newest first unless asc is set.
+// Entries without a time come last in both directions.
+func sortByTime(r []Entry, asc bool, fn func(e *Entry) time.Time) {
+	slices.SortStableFunc(r, func(a, b Entry) int {
+		ta, tb := fn(&a), fn(&b)
+
+		if ta.IsZero() != tb.IsZero() {
+			if ta.IsZero() {
+				return 1
+			}
+
+			return -1
+		}
+
+		c := ta.Compare(tb)
+		if !asc {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Path, b.Path)
+	})
+}
+
+// ReadTimes reads the times needed by the sort type into the entries, using the given number of workers
+// (defaults to the number of CPUs). Paths starting with "~" are relative to homeDir. Unreadable times are left unset.
+func ReadTimes(r []Entry, t SortType, homeDir string, threads int) {
+	var read func(e *Entry, path string)
+
+	switch t {
+	case MTimeSort, MTimeAscSort:
+		read = func(e *Entry, path string) {
+			if info, err := os.Stat(path); err == nil {
+				e.ModTime = info.ModTime()
+			}
+		}
+	case CommitSort, CommitAscSort:
+		read = func(e *Entry, path string) {
+			if info, err := git.ReadHead(path); err == nil {
+				e.CommitTime = info.CommitTime
+			}
+		}
+	default:
+		return
+	}
+
+	if threads <= 0 {
+		threads = runtime.GOMAXPROCS(0)
+	}
+
+	var wg sync.WaitGroup
+	next := make(chan int)
+
+	for range threads {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range next {
+				path := r[i].Path
+				if rest, ok := strings.CutPrefix(path, "~"); ok {
+					path = filepath.Join(homeDir, rest)
+				}
+
+				read(&r[i], path)
+			}
+		}()
+	}
+
+	for i := range r {
+		next <- i
+	}
+
+	close(next)
+	wg.Wait()
+}
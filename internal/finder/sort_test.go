@@ -0,0 +1,106 @@
+package finder
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSort(t *testing.T) {
+	scores := map[string]float64{"~/b": 2, "~/c": 8}
+	score := func(r string) float64 {
+		return scores[r]
+	}
+
+	tests := []struct {
+		sortType SortType
+		expected []string
+	}{
+		{NoSort, []string{"~/b", "~/d", "~/c", "~/a"}},
+		{AscSort, []string{"~/a", "~/b", "~/c", "~/d"}},
+		{DescSort, []string{"~/d", "~/c", "~/b", "~/a"}},
+		{FrecencySort, []string{"~/c", "~/b", "~/a", "~/d"}},
+	}
+
+	for _, tt := range tests {
+		r := []Entry{{Path: "~/b"}, {Path: "~/d"}, {Path: "~/c"}, {Path: "~/a"}}
+		Sort(r, tt.sortType, score)
+		assert.Equal(t, tt.expected, Paths(r))
+	}
+}
+
+func TestSortByTime(t *testing.T) {
+	at := func(sec int64) time.Time {
+		return time.Unix(sec, 0)
+	}
+
+	// Entries without a time come last in both directions, ties are ascending.
+	tests := []struct {
+		sortType SortType
+		expected []string
+	}{
+		{MTimeSort, []string{"~/c", "~/a", "~/d", "~/b"}},
+		{MTimeAscSort, []string{"~/a", "~/d", "~/c", "~/b"}},
+		{CommitSort, []string{"~/a", "~/b", "~/d", "~/c"}},
+		{CommitAscSort, []string{"~/d", "~/a", "~/b", "~/c"}},
+	}
+
+	for _, tt := range tests {
+		r := []Entry{
+			{Path: "~/b", CommitTime: at(20)},
+			{Path: "~/d", ModTime: at(10), CommitTime: at(5)},
+			{Path: "~/c", ModTime: at(30)},
+			{Path: "~/a", ModTime: at(10), CommitTime: at(20)},
+		}
+
+		Sort(r, tt.sortType, nil)
+		assert.Equal(t, tt.expected, Paths(r))
+	}
+}
+
+func TestReadTimes(t *testing.T) {
+	home := t.TempDir()
+	modTime := time.Unix(1700000000, 0)
+
+	project := filepath.Join(home, "project")
+	assert.NoError(t, os.Mkdir(project, 0755))
+	assert.NoError(t, os.Chtimes(project, modTime, modTime))
+
+	// Repository with a detached HEAD pointing to a loose commit.
+	repo := filepath.Join(home, "repo")
+	commit := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n" +
+		"author gsp <gsp@example.com> 1600000000 +0000\n" +
+		"committer gsp <gsp@example.com> 1650000000 +0200\n\ninit\n"
+	object := []byte(fmt.Sprintf("commit %d\x00%s", len(commit), commit))
+
+	hash := fmt.Sprintf("%x", sha1.Sum(object))
+	objectDir := filepath.Join(repo, ".git", "objects", hash[:2])
+	assert.NoError(t, os.MkdirAll(objectDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte(hash+"\n"), 0644))
+
+	compressed := new(bytes.Buffer)
+	zw := zlib.NewWriter(compressed)
+	_, err := zw.Write(object)
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, os.WriteFile(filepath.Join(objectDir, hash[2:]), compressed.Bytes(), 0644))
+
+	r := []Entry{{Path: "~/project"}, {Path: repo}, {Path: "~/missing"}}
+
+	ReadTimes(r, MTimeSort, home, 2)
+	assert.True(t, modTime.Equal(r[0].ModTime))
+	assert.False(t, r[1].ModTime.IsZero())
+	assert.True(t, r[2].ModTime.IsZero())
+
+	ReadTimes(r, CommitAscSort, home, 0)
+	assert.True(t, r[0].CommitTime.IsZero())
+	assert.True(t, time.Unix(1650000000, 0).Equal(r[1].CommitTime))
+	assert.True(t, r[2].CommitTime.IsZero())
+}
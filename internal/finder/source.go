@@ -1,30 +1,98 @@
 package finder
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync/atomic"
 
+	"github.com/gabefiori/gsp/internal/ignore"
 	"github.com/mitchellh/go-homedir"
 )
 
 var ErrInvalidFormatFn = errors.New("invalid formatFn")
 var ErrInvalidRoot = errors.New("invalid root")
 
+// IgnoreFiles are the files read in each directory when [Source.Gitignore] is set.
+// Patterns of later files take precedence.
+var IgnoreFiles = []string{".gitignore", ".ignore", ".fdignore"}
+
 // Source represents a directory source for finding paths.
 type Source struct {
 	Path         string
 	OriginalPath string
 	Depth        uint8
 
+	// Entries (e.g. ".git", "go.mod") that identify a project root.
+	// When set, only directories containing at least one marker are emitted.
+	Markers []string
+
+	// Stop descending into a directory once it is identified as a project root.
+	// Only takes effect when markers are set.
+	StopAtMarker bool
+
+	// Gitignore-style patterns, relative to the source path.
+	// Matching directories are neither emitted nor walked.
+	Exclude []string
+
+	// Read ignore files (see [IgnoreFiles]) in each visited directory
+	// and skip the directories they match.
+	Gitignore bool
+
+	// How hidden directories are handled. The source path itself is never considered hidden.
+	Hidden HiddenMode
+
+	exclude *ignore.Matcher
+
+	// Directories already emitted by any source of the run.
+	// When set, entries resolving to the same physical directory are skipped.
+	seen *fileIDSet
+
+	// Listings of unchanged directories. Optional.
+	dirCache *DirCache
+
+	// Set once an error stops the walk of the source.
+	// The remaining directories of the source are discarded by the walker.
+	stopped *atomic.Bool
+
 	// Function to format the output path.
 	// Allows flexibility in other parts of the codebase (e.g., for testing).
 	formatFn func(string) string
-	resultCh chan<- string
+	resultCh chan<- Entry
+	errCh    chan<- error
+	ctx      context.Context
 }
 
 // Find initiates the search based on the specified depth and format function.
-func (s *Source) Find(resultCh chan<- string, formatFn func(string) string) error {
+//
+// Unreadable entries found while walking are sent to errCh and skipped.
+// When errCh is nil, the first of these errors stops the search and is returned.
+// Errors on the source path itself are always returned.
+//
+// The search stops with the context error once ctx is done.
+// Directories are walked one at a time; see [Run] for walking in parallel.
+func (s *Source) Find(ctx context.Context, resultCh chan<- Entry, errCh chan<- error, formatFn func(string) string) error {
+	if err := s.prepare(ctx, resultCh, errCh, formatFn); err != nil {
+		return err
+	}
+
+	var findErr error
+
+	w := newWalker(1, func(_ *Source, err error) {
+		findErr = err
+	})
+
+	w.push(s.rootDir())
+	w.run()
+
+	return findErr
+}
+
+// prepare validates the source and sets up the state needed to walk it.
+func (s *Source) prepare(ctx context.Context, resultCh chan<- Entry, errCh chan<- error, formatFn func(string) string) error {
 	if formatFn == nil {
 		return ErrInvalidFormatFn
 	}
@@ -38,83 +106,252 @@ func (s *Source) Find(resultCh chan<- string, formatFn func(string) string) erro
 
 	s.Path = expanded
 	s.resultCh = resultCh
+	s.errCh = errCh
+	s.ctx = ctx
+	s.stopped = new(atomic.Bool)
 
-	err = s.walkZero(s.Path)
+	s.exclude, err = new(ignore.Matcher).Append(s.Path, s.Exclude)
 	if err != nil {
 		return err
 	}
 
-	if s.Depth == 0 {
-		return nil
+	isDir, err := isPathDir(s.Path)
+	if err != nil {
+		return err
+	}
+
+	if !isDir {
+		return fmt.Errorf("%w %q", ErrInvalidRoot, s.OriginalPath)
 	}
 
-	return s.walk(s.Path, 0)
+	return nil
 }
 
-func (s *Source) walkZero(root string) error {
-	isDir, err := isPathDir(root)
-	if err != nil {
+// dir is a directory waiting to be visited by the walker.
+type dir struct {
+	source *Source
+	path   string
+	depth  uint8
+
+	// Patterns of the ignore files found in the parent directories.
+	ign *ignore.Matcher
+
+	// Identifiers of the parent directories, used to detect symlink cycles.
+	ancestors []fileID
+
+	// Whether the directory is a symbolic link.
+	symlink bool
+}
+
+func (s *Source) rootDir() dir {
+	return dir{source: s, path: s.Path}
+}
+
+// visit emits d and pushes its subdirectories to be visited next.
+func (s *Source) visit(d dir, push func(dir)) error {
+	if err := s.ctx.Err(); err != nil {
 		return err
 	}
 
-	if isDir {
-		s.resultCh <- s.formatFn(root)
-		return nil
+	var entries []os.DirEntry
+	descend := d.depth < s.Depth
+
+	if d.depth > 0 && s.Hidden == HiddenNoDescend && isHidden(filepath.Base(d.path)) {
+		descend = false
 	}
 
-	return ErrInvalidRoot
-}
+	var id fileID
+	var hasID bool
+	var err error
+
+	if descend {
+		id, hasID, entries, err = readDir(d.path, s.dirCache)
+	} else if s.seen != nil {
+		id, hasID, err = statID(d.path)
+	}
 
-func (s *Source) walk(root string, currDepth uint8) error {
-	entries, err := os.ReadDir(root)
 	if err != nil {
-		return err
+		if d.depth == 0 {
+			return err
+		}
+
+		return s.report(err)
 	}
 
-	walkNext := func(p string) error {
-		s.resultCh <- s.formatFn(p)
+	isProject := s.isProject(d.path, entries, descend)
+	emit := isProject
+
+	// Already found through another path: emitted once, and only walked again with more levels left.
+	if hasID && s.seen != nil {
+		remaining := 0
+		if descend {
+			remaining = int(s.Depth - d.depth)
+		}
+
+		var again bool
+		emit, again = s.seen.visit(id, remaining, isProject)
+		descend = descend && again
+	}
 
-		if currDepth+1 < s.Depth {
-			return s.walk(p, currDepth+1)
+	if emit {
+		select {
+		case s.resultCh <- s.entry(d):
+		case <-s.ctx.Done():
+			return s.ctx.Err()
 		}
+	}
 
+	if !descend || (isProject && s.StopAtMarker && len(s.Markers) > 0) {
 		return nil
 	}
 
-	for _, entry := range entries {
-		joined := filepath.Join(root, entry.Name())
+	ign := d.ign
+	if s.Gitignore {
+		ign, err = s.readIgnoreFiles(d.path, entries, ign)
+		if err != nil {
+			return err
+		}
+	}
+
+	ancestors := d.ancestors
+	if hasID {
+		ancestors = append(ancestors[:len(ancestors):len(ancestors)], id)
+	}
+
+	// Pushed in reverse order, so a single worker visits them in order.
+	for _, entry := range slices.Backward(entries) {
+		if s.Hidden == HiddenSkip && isHidden(entry.Name()) {
+			continue
+		}
+
+		joined := filepath.Join(d.path, entry.Name())
+		next := dir{source: s, path: joined, depth: d.depth + 1, ign: ign, ancestors: ancestors}
 
 		if entry.IsDir() {
-			if err := walkNext(joined); err != nil {
-				return err
+			if !s.skip(joined, ign) {
+				push(next)
 			}
 
 			continue
 		}
 
 		// is a symlink
-		info, err := entry.Info()
+		if entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		target, err := os.Stat(joined)
 		if err != nil {
-			return err
+			if err := s.report(err); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !target.IsDir() || s.skip(joined, ign) {
+			continue
+		}
+
+		// Points to a parent directory, which would be walked again until the depth is exhausted.
+		if targetID, ok := fileIDOf(target); ok && slices.Contains(ancestors, targetID) {
+			continue
 		}
 
-		if info.Mode()&os.ModeSymlink == 0 {
+		next.symlink = true
+		push(next)
+	}
+
+	return nil
+}
+
+func (s *Source) entry(d dir) Entry {
+	return Entry{
+		Path:    s.formatFn(d.path),
+		Source:  s.OriginalPath,
+		Depth:   d.depth,
+		Symlink: d.symlink,
+	}
+}
+
+// skip reports whether the directory p is excluded by the source patterns or ignore files.
+func (s *Source) skip(p string, ign *ignore.Matcher) bool {
+	return s.exclude.Match(p, true) || ign.Match(p, true)
+}
+
+// report sends a non-fatal error to the error channel, so the walk can skip the entry.
+// Without an error channel, the error is returned and the walk stops.
+func (s *Source) report(err error) error {
+	if s.errCh == nil {
+		return err
+	}
+
+	select {
+	case s.errCh <- err:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// readIgnoreFiles stacks the patterns of the ignore files present in entries on top of ign.
+func (s *Source) readIgnoreFiles(dir string, entries []os.DirEntry, ign *ignore.Matcher) (*ignore.Matcher, error) {
+	for _, name := range IgnoreFiles {
+		if !containsEntry(entries, name) {
 			continue
 		}
 
-		isDir, err := isPathDir(joined)
+		data, err := os.ReadFile(filepath.Join(dir, name))
 		if err != nil {
-			return err
+			if err := s.report(err); err != nil {
+				return nil, err
+			}
+
+			continue
 		}
 
-		if isDir {
-			if err := walkNext(joined); err != nil {
-				return err
+		ign = ign.AppendFile(dir, data)
+	}
+
+	return ign, nil
+}
+
+func containsEntry(entries []os.DirEntry, name string) bool {
+	for _, entry := range entries {
+		if entry.Name() == name && !entry.IsDir() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isProject reports whether dir contains one of the source markers.
+// When entries were not read (listed is false), each marker is checked with a stat call instead.
+func (s *Source) isProject(dir string, entries []os.DirEntry, listed bool) bool {
+	if len(s.Markers) == 0 {
+		return true
+	}
+
+	if !listed {
+		for _, m := range s.Markers {
+			if _, err := os.Lstat(filepath.Join(dir, m)); err == nil {
+				return true
 			}
 		}
+
+		return false
 	}
 
-	return nil
+	for _, entry := range entries {
+		for _, m := range s.Markers {
+			if entry.Name() == m {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 func isPathDir(path string) (bool, error) {
@@ -1,6 +1,7 @@
 package finder
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -66,11 +67,11 @@ func TestFind(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(fmt.Sprintf("Depth %d", tt.depth), func(t *testing.T) {
 			source := Source{OriginalPath: tempDir, Depth: tt.depth}
-			resultCh := make(chan string)
+			resultCh := make(chan Entry)
 
 			go func() {
 				defer close(resultCh)
-				err := source.Find(resultCh, func(s string) string {
+				err := source.Find(context.Background(), resultCh, nil, func(s string) string {
 					return s
 				})
 
@@ -78,8 +79,8 @@ func TestFind(t *testing.T) {
 			}()
 
 			var paths []string
-			for path := range resultCh {
-				paths = append(paths, path)
+			for e := range resultCh {
+				paths = append(paths, e.Path)
 			}
 
 			for _, expected := range tt.expected {
@@ -90,3 +91,287 @@ func TestFind(t *testing.T) {
 		})
 	}
 }
+
+func TestFindMarkers(t *testing.T) {
+	tempDir := t.TempDir()
+
+	projectDir := filepath.Join(tempDir, "project")
+	nestedDir := filepath.Join(projectDir, "nested")
+	plainDir := filepath.Join(tempDir, "plain")
+	plainNestedDir := filepath.Join(plainDir, "nested")
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(nestedDir, ".git"), 0755))
+	assert.NoError(t, os.MkdirAll(plainNestedDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(projectDir, "go.mod"), nil, 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(plainNestedDir, "go.mod"), nil, 0644))
+
+	tests := []struct {
+		name         string
+		depth        uint8
+		stopAtMarker bool
+		expected     []string
+	}{
+		{
+			name:     "Depth 1",
+			depth:    1,
+			expected: []string{projectDir},
+		},
+		{
+			name:     "Depth 2",
+			depth:    2,
+			expected: []string{projectDir, nestedDir, plainNestedDir},
+		},
+		{
+			name:         "Depth 2 stop at marker",
+			depth:        2,
+			stopAtMarker: true,
+			expected:     []string{projectDir, plainNestedDir},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := Source{
+				OriginalPath: tempDir,
+				Depth:        tt.depth,
+				Markers:      []string{".git", "go.mod"},
+				StopAtMarker: tt.stopAtMarker,
+			}
+
+			assert.ElementsMatch(t, tt.expected, findPaths(t, source))
+		})
+	}
+}
+
+func TestFindExclude(t *testing.T) {
+	tempDir := t.TempDir()
+
+	appDir := filepath.Join(tempDir, "app")
+	modulesDir := filepath.Join(appDir, "node_modules")
+	buildDir := filepath.Join(tempDir, "build")
+	libDir := filepath.Join(tempDir, "lib")
+	libBuildDir := filepath.Join(libDir, "build")
+
+	for _, dir := range []string{filepath.Join(modulesDir, "pkg"), buildDir, libBuildDir} {
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+	}
+
+	source := Source{
+		OriginalPath: tempDir,
+		Depth:        3,
+		Exclude:      []string{"node_modules", "/build"},
+	}
+
+	assert.ElementsMatch(t, []string{tempDir, appDir, libDir, libBuildDir}, findPaths(t, source))
+}
+
+func TestFindGitignore(t *testing.T) {
+	tempDir := t.TempDir()
+
+	repoDir := filepath.Join(tempDir, "repo")
+	distDir := filepath.Join(repoDir, "dist")
+	pkgDir := filepath.Join(repoDir, "pkg")
+	pkgOutDir := filepath.Join(pkgDir, "out")
+	pkgDistDir := filepath.Join(pkgDir, "dist")
+	otherOutDir := filepath.Join(tempDir, "other", "out")
+
+	for _, dir := range []string{distDir, pkgOutDir, pkgDistDir, otherOutDir} {
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+	}
+
+	assert.NoError(t, os.WriteFile(filepath.Join(repoDir, ".gitignore"), []byte("dist/\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(pkgDir, ".ignore"), []byte("out\n"), 0644))
+
+	tests := []struct {
+		name      string
+		gitignore bool
+		expected  []string
+	}{
+		{
+			name:      "Disabled",
+			gitignore: false,
+			expected: []string{
+				tempDir, repoDir, distDir, pkgDir, pkgOutDir, pkgDistDir,
+				filepath.Dir(otherOutDir), otherOutDir,
+			},
+		},
+		{
+			name:      "Enabled",
+			gitignore: true,
+			expected: []string{
+				tempDir, repoDir, pkgDir, filepath.Dir(otherOutDir), otherOutDir,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := Source{OriginalPath: tempDir, Depth: 3, Gitignore: tt.gitignore}
+			assert.ElementsMatch(t, tt.expected, findPaths(t, source))
+		})
+	}
+}
+
+func TestFindHidden(t *testing.T) {
+	tempDir := t.TempDir()
+
+	visibleDir := filepath.Join(tempDir, "visible")
+	hiddenDir := filepath.Join(tempDir, ".hidden")
+	hiddenNestedDir := filepath.Join(hiddenDir, "nested")
+	visibleHiddenDir := filepath.Join(visibleDir, ".venv")
+
+	for _, dir := range []string{hiddenNestedDir, visibleHiddenDir} {
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+	}
+
+	tests := []struct {
+		name     string
+		mode     HiddenMode
+		expected []string
+	}{
+		{
+			name:     "Include",
+			mode:     HiddenInclude,
+			expected: []string{tempDir, visibleDir, visibleHiddenDir, hiddenDir, hiddenNestedDir},
+		},
+		{
+			name:     "Skip",
+			mode:     HiddenSkip,
+			expected: []string{tempDir, visibleDir},
+		},
+		{
+			name:     "No descend",
+			mode:     HiddenNoDescend,
+			expected: []string{tempDir, visibleDir, visibleHiddenDir, hiddenDir},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := Source{OriginalPath: tempDir, Depth: 2, Hidden: tt.mode}
+			assert.ElementsMatch(t, tt.expected, findPaths(t, source))
+		})
+	}
+}
+
+// findPaths runs [Source.Find] and collects the unformatted results.
+func findPaths(t *testing.T, source Source) []string {
+	resultCh := make(chan Entry)
+
+	go func() {
+		defer close(resultCh)
+		err := source.Find(context.Background(), resultCh, nil, func(s string) string {
+			return s
+		})
+
+		assert.NoError(t, err)
+	}()
+
+	var paths []string
+	for e := range resultCh {
+		paths = append(paths, e.Path)
+	}
+
+	return paths
+}
+
+func TestFindEntries(t *testing.T) {
+	tempDir := t.TempDir()
+
+	projectDir := filepath.Join(tempDir, "project")
+	linkDir := filepath.Join(tempDir, "link")
+
+	assert.NoError(t, os.Mkdir(projectDir, 0755))
+	assert.NoError(t, os.Symlink(projectDir, linkDir))
+
+	source := Source{OriginalPath: tempDir, Depth: 1}
+	resultCh := make(chan Entry)
+
+	go func() {
+		defer close(resultCh)
+		err := source.Find(context.Background(), resultCh, nil, func(s string) string {
+			return s
+		})
+
+		assert.NoError(t, err)
+	}()
+
+	var entries []Entry
+	for e := range resultCh {
+		entries = append(entries, e)
+	}
+
+	assert.ElementsMatch(t, []Entry{
+		{Path: tempDir, Source: tempDir, Depth: 0},
+		{Path: projectDir, Source: tempDir, Depth: 1},
+		{Path: linkDir, Source: tempDir, Depth: 1, Symlink: true},
+	}, entries)
+}
+
+func TestFindErrors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	validDir := filepath.Join(tempDir, "valid")
+	danglingLink := filepath.Join(tempDir, "dangling")
+
+	assert.NoError(t, os.Mkdir(validDir, 0755))
+	assert.NoError(t, os.Symlink(filepath.Join(tempDir, "missing"), danglingLink))
+
+	t.Run("Skip and report", func(t *testing.T) {
+		source := Source{OriginalPath: tempDir, Depth: 1}
+		resultCh := make(chan Entry)
+		errCh := make(chan error, 1)
+
+		go func() {
+			defer close(resultCh)
+			err := source.Find(context.Background(), resultCh, errCh, func(s string) string {
+				return s
+			})
+
+			assert.NoError(t, err)
+		}()
+
+		var paths []string
+		for e := range resultCh {
+			paths = append(paths, e.Path)
+		}
+
+		assert.ElementsMatch(t, []string{tempDir, validDir}, paths)
+		assert.ErrorIs(t, <-errCh, os.ErrNotExist)
+	})
+
+	t.Run("Fail fast", func(t *testing.T) {
+		source := Source{OriginalPath: tempDir, Depth: 1}
+		resultCh := make(chan Entry, 2)
+
+		err := source.Find(context.Background(), resultCh, nil, func(s string) string {
+			return s
+		})
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Invalid root", func(t *testing.T) {
+		source := Source{OriginalPath: danglingLink, Depth: 1}
+		err := source.Find(context.Background(), make(chan Entry), make(chan error), func(s string) string {
+			return s
+		})
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestFindSymlinkCycle(t *testing.T) {
+	tempDir := t.TempDir()
+
+	projectDir := filepath.Join(tempDir, "project")
+	nestedDir := filepath.Join(projectDir, "nested")
+	assert.NoError(t, os.MkdirAll(nestedDir, 0755))
+
+	// Both point to ancestors of the symlink itself.
+	assert.NoError(t, os.Symlink(tempDir, filepath.Join(nestedDir, "to_root")))
+	assert.NoError(t, os.Symlink(projectDir, filepath.Join(nestedDir, "to_project")))
+
+	source := Source{OriginalPath: tempDir, Depth: 10}
+	assert.ElementsMatch(t, []string{tempDir, projectDir, nestedDir}, findPaths(t, source))
+}
@@ -0,0 +1,109 @@
+package finder
+
+import "sync"
+
+// walker visits the directories of one or more sources with a bounded pool of workers.
+// Directories are taken from a shared queue, so a single large source is walked in parallel too.
+type walker struct {
+	threads int
+
+	// Called once for each source stopped by an error, including context errors.
+	onError func(s *Source, err error)
+
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// Directories waiting to be visited, used as a stack to keep memory usage low.
+	queue []dir
+
+	// Directories queued or being visited. The walk is done once it reaches zero.
+	pending int
+}
+
+func newWalker(threads int, onError func(s *Source, err error)) *walker {
+	w := &walker{
+		threads: max(threads, 1),
+		onError: onError,
+	}
+
+	w.cond = sync.NewCond(&w.mu)
+	return w
+}
+
+func (w *walker) push(d dir) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.queue = append(w.queue, d)
+	w.pending++
+	w.cond.Signal()
+}
+
+// pop waits for the next directory. It fails once the walk is done.
+func (w *walker) pop() (dir, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for len(w.queue) == 0 && w.pending > 0 {
+		w.cond.Wait()
+	}
+
+	if len(w.queue) == 0 {
+		return dir{}, false
+	}
+
+	last := len(w.queue) - 1
+	d := w.queue[last]
+
+	w.queue[last] = dir{}
+	w.queue = w.queue[:last]
+
+	return d, true
+}
+
+func (w *walker) done() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending--
+	if w.pending == 0 {
+		w.cond.Broadcast()
+	}
+}
+
+// run starts the workers and waits until every pushed directory, and its subdirectories, are visited.
+func (w *walker) run() {
+	var wg sync.WaitGroup
+
+	for range w.threads {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				d, ok := w.pop()
+				if !ok {
+					return
+				}
+
+				w.visit(d)
+				w.done()
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (w *walker) visit(d dir) {
+	s := d.source
+	if s.stopped.Load() {
+		return
+	}
+
+	err := s.visit(d, w.push)
+	if err != nil && s.stopped.CompareAndSwap(false, true) {
+		w.onError(s, err)
+	}
+}
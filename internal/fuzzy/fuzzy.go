@@ -0,0 +1,308 @@
+// Package fuzzy implements fuzzy matching with the scoring scheme used by fzf.
+package fuzzy
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Scores and bonuses, as defined by fzf.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	// Matching the first character of a word, or a character right after a separator.
+	bonusBoundary          = scoreMatch / 2
+	bonusBoundaryWhite     = bonusBoundary + 2
+	bonusBoundaryDelimiter = bonusBoundary + 1
+
+	// Non-word characters are rarely typed, so matching one is also rewarded.
+	bonusNonWord = scoreMatch / 2
+
+	// Matching the start of a camelCase word or a number.
+	bonusCamel123 = bonusBoundary + scoreGapExtension
+
+	// Minimum bonus for a consecutive match, so it beats a gap.
+	bonusConsecutive = -(scoreGapStart + scoreGapExtension)
+
+	// The bonus of the first pattern character is multiplied, since it is the most meaningful.
+	bonusFirstCharMultiplier = 2
+)
+
+// Characters separating path components and similar fields.
+const delimiters = "/,:;|"
+
+type charClass int8
+
+const (
+	charWhite charClass = iota
+	charNonWord
+	charDelimiter
+	charLower
+	charUpper
+	charLetter
+	charNumber
+)
+
+func classOf(r rune) charClass {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return charLower
+	case r >= 'A' && r <= 'Z':
+		return charUpper
+	case r >= '0' && r <= '9':
+		return charNumber
+	case unicode.IsSpace(r):
+		return charWhite
+	case strings.ContainsRune(delimiters, r):
+		return charDelimiter
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsLetter(r):
+		return charLetter
+	case unicode.IsNumber(r):
+		return charNumber
+	default:
+		return charNonWord
+	}
+}
+
+func bonusFor(prev, curr charClass) int {
+	if curr > charNonWord {
+		switch prev {
+		case charWhite:
+			return bonusBoundaryWhite
+		case charDelimiter:
+			return bonusBoundaryDelimiter
+		case charNonWord:
+			return bonusBoundary
+		}
+	}
+
+	if prev == charLower && curr == charUpper || prev != charNumber && curr == charNumber {
+		return bonusCamel123
+	}
+
+	switch curr {
+	case charNonWord, charDelimiter:
+		return bonusNonWord
+	case charWhite:
+		return bonusBoundaryWhite
+	}
+
+	return 0
+}
+
+// Match matches the pattern against text.
+//
+// The pattern is split into space-separated terms, and every term must match.
+// Matching is case-insensitive, unless the pattern contains an uppercase letter.
+//
+// It returns the score (higher is better) and the indexes of the matched runes in text, in ascending order.
+// An empty pattern matches everything with a zero score.
+func Match(pattern, text string) (score int, positions []int, ok bool) {
+	terms := strings.Fields(pattern)
+	if len(terms) == 0 {
+		return 0, nil, true
+	}
+
+	caseSensitive := strings.IndexFunc(pattern, unicode.IsUpper) != -1
+	runes := []rune(text)
+
+	for _, term := range terms {
+		s, pos, ok := matchTerm([]rune(term), runes, caseSensitive)
+		if !ok {
+			return 0, nil, false
+		}
+
+		score += s
+		positions = append(positions, pos...)
+	}
+
+	if len(terms) > 1 {
+		slices.Sort(positions)
+		positions = slices.Compact(positions)
+	}
+
+	return score, positions, true
+}
+
+// matchTerm finds the shortest match of pattern in text, like fzf's FuzzyMatchV1:
+// a forward scan finds where the first occurrence ends, and a backward scan from there finds where it starts.
+func matchTerm(pattern, text []rune, caseSensitive bool) (int, []int, bool) {
+	char := func(i int) rune {
+		if caseSensitive {
+			return text[i]
+		}
+
+		return unicode.ToLower(text[i])
+	}
+
+	if !caseSensitive {
+		for i, r := range pattern {
+			pattern[i] = unicode.ToLower(r)
+		}
+	}
+
+	sidx, eidx, pidx := -1, -1, 0
+
+	for i := range text {
+		if char(i) != pattern[pidx] {
+			continue
+		}
+
+		if sidx < 0 {
+			sidx = i
+		}
+
+		if pidx++; pidx == len(pattern) {
+			eidx = i + 1
+			break
+		}
+	}
+
+	if eidx < 0 {
+		return 0, nil, false
+	}
+
+	pidx--
+	for i := eidx - 1; i >= sidx; i-- {
+		if char(i) != pattern[pidx] {
+			continue
+		}
+
+		if pidx--; pidx < 0 {
+			sidx = i
+			break
+		}
+	}
+
+	score, positions := calculateScore(pattern, text, sidx, eidx, char)
+	return score, positions, true
+}
+
+func calculateScore(pattern, text []rune, sidx, eidx int, char func(int) rune) (int, []int) {
+	positions := make([]int, 0, len(pattern))
+	score, pidx, consecutive, firstBonus := 0, 0, 0, 0
+	inGap := false
+
+	prevClass := charWhite
+	if sidx > 0 {
+		prevClass = classOf(text[sidx-1])
+	}
+
+	for i := sidx; i < eidx; i++ {
+		class := classOf(text[i])
+
+		if pidx < len(pattern) && char(i) == pattern[pidx] {
+			positions = append(positions, i)
+			score += scoreMatch
+
+			bonus := bonusFor(prevClass, class)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				// Break consecutive chunks at a boundary.
+				if bonus >= bonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+
+				bonus = max(bonus, firstBonus, bonusConsecutive)
+			}
+
+			if pidx == 0 {
+				score += bonus * bonusFirstCharMultiplier
+			} else {
+				score += bonus
+			}
+
+			inGap = false
+			consecutive++
+			pidx++
+		} else {
+			if inGap {
+				score += scoreGapExtension
+			} else {
+				score += scoreGapStart
+			}
+
+			inGap = true
+			consecutive = 0
+			firstBonus = 0
+		}
+
+		prevClass = class
+	}
+
+	return score, positions
+}
+
+// Result is a matched item.
+type Result struct {
+	// Index of the item in the ranked slice.
+	Index int
+
+	Score     int
+	Positions []int
+}
+
+// Rank matches the pattern against every item, returning the matches sorted like fzf:
+// by score, then by length (shorter first), then by index.
+func Rank(pattern string, items []string) []Result {
+	return RankFrom(pattern, items, nil, 0)
+}
+
+// RankFrom matches the pattern against the items from index from, and merges the matches into ranked,
+// the result of [Rank] for the same pattern and the items before from. It returns the ranking of all items,
+// so items received over time are only matched once.
+func RankFrom(pattern string, items []string, ranked []Result, from int) []Result {
+	var results []Result
+
+	for i := from; i < len(items); i++ {
+		score, positions, ok := Match(pattern, items[i])
+		if ok {
+			results = append(results, Result{Index: i, Score: score, Positions: positions})
+		}
+	}
+
+	if strings.TrimSpace(pattern) == "" {
+		return append(ranked, results...)
+	}
+
+	compare := func(a, b Result) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+
+		if c := cmp.Compare(len(items[a.Index]), len(items[b.Index])); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.Index, b.Index)
+	}
+
+	slices.SortFunc(results, compare)
+
+	if len(ranked) == 0 {
+		return results
+	}
+
+	merged := make([]Result, 0, len(ranked)+len(results))
+	for len(ranked) > 0 && len(results) > 0 {
+		if compare(ranked[0], results[0]) <= 0 {
+			merged = append(merged, ranked[0])
+			ranked = ranked[1:]
+		} else {
+			merged = append(merged, results[0])
+			results = results[1:]
+		}
+	}
+
+	merged = append(merged, ranked...)
+	return append(merged, results...)
+}
@@ -0,0 +1,88 @@
+package fuzzy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		{"Empty pattern", "", "~/src/gsp", true, nil},
+		{"Consecutive", "gsp", "~/src/gsp", true, []int{6, 7, 8}},
+		{"Scattered", "sgp", "~/src/gsp", true, []int{2, 6, 8}},
+		{"Shortest match", "gsp", "~/gabe/src/gsp", true, []int{11, 12, 13}},
+		{"No match", "xyz", "~/src/gsp", false, nil},
+		{"Case insensitive", "gsp", "~/src/GSP", true, []int{6, 7, 8}},
+		{"Smart case", "Gsp", "~/src/gsp", false, nil},
+		{"Multiple terms", "gsp src", "~/src/gsp", true, []int{2, 3, 4, 6, 7, 8}},
+		{"Multiple terms no match", "gsp api", "~/src/gsp", false, nil},
+		{"Unicode", "cafe", "~/café/cafe", true, []int{7, 8, 9, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, positions, ok := Match(tt.pattern, tt.text)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.positions, positions)
+		})
+	}
+}
+
+func TestMatch_Score(t *testing.T) {
+	score := func(pattern, text string) int {
+		s, _, ok := Match(pattern, text)
+		assert.True(t, ok)
+		return s
+	}
+
+	// Same values as fzf's FuzzyMatchV1.
+	assert.Equal(t, scoreMatch*3+bonusBoundaryDelimiter*bonusFirstCharMultiplier+bonusBoundaryDelimiter*2, score("api", "~/src/api"))
+
+	assert.Greater(t, score("api", "~/src/api"), score("api", "~/src/rapid"))
+	assert.Greater(t, score("api", "~/src/api"), score("api", "~/src/a-p-i"))
+	assert.Greater(t, score("gw", "~/src/api-gateway"), score("gw", "~/src/bigwig"))
+}
+
+func TestRank(t *testing.T) {
+	items := []string{"~/src/rapid", "~/src/api-gateway", "~/src/api", "~/src/web"}
+
+	var ranked []string
+	for _, r := range Rank("api", items) {
+		ranked = append(ranked, items[r.Index])
+	}
+
+	assert.Equal(t, []string{"~/src/api", "~/src/api-gateway", "~/src/rapid"}, ranked)
+
+	t.Run("Empty pattern keeps the order", func(t *testing.T) {
+		results := Rank(" ", items)
+		assert.Len(t, results, len(items))
+
+		for i, r := range results {
+			assert.Equal(t, i, r.Index)
+		}
+	})
+}
+
+func TestRankFrom(t *testing.T) {
+	items := []string{"~/src/rapid", "~/src/api-gateway", "~/work/api", "~/src/web", "~/src/api", "~/work/rapid-api"}
+
+	for _, pattern := range []string{"api", "rapid", "", "xyz"} {
+		// Items received in batches are ranked like all items at once.
+		var ranked []Result
+		from := 0
+
+		for _, to := range []int{2, 3, 6} {
+			ranked = RankFrom(pattern, items[:to], ranked, from)
+			from = to
+		}
+
+		assert.Equal(t, Rank(pattern, items), ranked, pattern)
+	}
+}
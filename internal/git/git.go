@@ -0,0 +1,65 @@
+// Package git reads information about git repositories directly from their files,
+// without running git.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNotRepository = errors.New("not a git repository")
+
+// Dir returns the git directory of the repository rooted at dir.
+// Worktrees and submodules, where ".git" is a file pointing to the git directory, are supported.
+func Dir(dir string) (string, error) {
+	p := filepath.Join(dir, ".git")
+
+	info, err := os.Stat(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrNotRepository
+		}
+
+		return "", err
+	}
+
+	if info.IsDir() {
+		return p, nil
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+
+	gitDir, ok := strings.CutPrefix(string(bytes.TrimSpace(data)), "gitdir: ")
+	if !ok {
+		return "", ErrNotRepository
+	}
+
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+
+	return gitDir, nil
+}
+
+// Head returns the branch checked out in gitDir.
+// When HEAD is detached, the branch is empty and the commit hash is returned instead.
+func Head(gitDir string) (branch, hash string, err error) {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", "", err
+	}
+
+	head := string(bytes.TrimSpace(data))
+
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/"), "", nil
+	}
+
+	return "", head, nil
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDir(t *testing.T) {
+	tempDir := t.TempDir()
+
+	repo := filepath.Join(tempDir, "repo")
+	worktree := filepath.Join(tempDir, "worktree")
+	plain := filepath.Join(tempDir, "plain")
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0755))
+	assert.NoError(t, os.Mkdir(worktree, 0755))
+	assert.NoError(t, os.Mkdir(plain, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: ../repo/.git/worktrees/wt\n"), 0644))
+
+	tests := []struct {
+		dir      string
+		expected string
+		err      error
+	}{
+		{dir: repo, expected: filepath.Join(repo, ".git")},
+		{dir: worktree, expected: filepath.Join(repo, ".git", "worktrees", "wt")},
+		{dir: plain, err: ErrNotRepository},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.dir), func(t *testing.T) {
+			gitDir, err := Dir(tt.dir)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, gitDir)
+		})
+	}
+}
+
+func TestHead(t *testing.T) {
+	tests := []struct {
+		name   string
+		head   string
+		branch string
+		hash   string
+	}{
+		{name: "Branch", head: "ref: refs/heads/main\n", branch: "main"},
+		{name: "Nested branch", head: "ref: refs/heads/feat/preview\n", branch: "feat/preview"},
+		{name: "Detached", head: "4b825dc642cb6eb9a060e54bf8d69288fbee4904\n", hash: "4b825dc642cb6eb9a060e54bf8d69288fbee4904"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitDir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte(tt.head), 0644))
+
+			branch, hash, err := Head(gitDir)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.branch, branch)
+			assert.Equal(t, tt.hash, hash)
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Object types of pack entries.
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+// Maximum length of the delta chains, which git limits to 50 by default.
+const maxDeltaDepth = 100
+
+var typeNames = map[byte]string{objCommit: "commit", objTree: "tree", objBlob: "blob", objTag: "tag"}
+
+// CommitTime returns the committer date of the commit with the given hash.
+// Objects are read from the loose objects and the packs of the repository.
+func CommitTime(gitDir, hash string) (time.Time, error) {
+	typ, data, err := readObject(commonDir(gitDir), hash, 0)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if typ != "commit" {
+		return time.Time{}, fmt.Errorf("%s is a %s, not a commit", hash, typ)
+	}
+
+	// Headers end with an empty line, before the message.
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+
+		committer, ok := strings.CutPrefix(line, "committer ")
+		if !ok {
+			continue
+		}
+
+		// "Name <email> <timestamp> <timezone>"
+		fields := strings.Fields(committer[strings.LastIndexByte(committer, '>')+1:])
+		if len(fields) != 2 {
+			break
+		}
+
+		sec, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return time.Unix(sec, 0), nil
+	}
+
+	return time.Time{}, fmt.Errorf("commit %s has no committer", hash)
+}
+
+// readObject returns the type and the content of an object.
+func readObject(dir, hash string, depth int) (string, []byte, error) {
+	if depth > maxDeltaDepth {
+		return "", nil, errors.New("delta chain too long")
+	}
+
+	if len(hash) < 4 {
+		return "", nil, ErrNotFound
+	}
+
+	typ, data, err := readLooseObject(filepath.Join(dir, "objects", hash[:2], hash[2:]))
+	if !errors.Is(err, os.ErrNotExist) {
+		return typ, data, err
+	}
+
+	id, err := hex.DecodeString(hash)
+	if err != nil {
+		return "", nil, err
+	}
+
+	idxs, err := filepath.Glob(filepath.Join(dir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, idx := range idxs {
+		offset, err := findInIndex(idx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		return readPackObject(dir, strings.TrimSuffix(idx, ".idx")+".pack", offset, len(id), depth)
+	}
+
+	return "", nil, ErrNotFound
+}
+
+func readLooseObject(path string) (string, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return "", nil, err
+	}
+
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// "<type> <size>\x00<content>"
+	header, content, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return "", nil, fmt.Errorf("invalid object %s", path)
+	}
+
+	typ, _, _ := strings.Cut(string(header), " ")
+
+	return typ, content, nil
+}
+
+// findInIndex returns the offset of an object in the pack of a version 2 index.
+func findInIndex(path string, id []byte) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer f.Close()
+
+	header := make([]byte, 8+256*4)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return 0, err
+	}
+
+	if !bytes.Equal(header[:8], []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}) {
+		return 0, fmt.Errorf("unsupported pack index %s", path)
+	}
+
+	// The fanout table holds the number of objects whose first byte is lower or equal to each value.
+	fanout := func(b int) int64 {
+		if b < 0 {
+			return 0
+		}
+
+		return int64(binary.BigEndian.Uint32(header[8+b*4:]))
+	}
+
+	count := fanout(255)
+	hashLen := int64(len(id))
+	namesStart := int64(len(header))
+
+	lo, hi := fanout(int(id[0])-1), fanout(int(id[0]))
+	name := make([]byte, hashLen)
+
+	for lo < hi {
+		mid := (lo + hi) / 2
+
+		if _, err := f.ReadAt(name, namesStart+mid*hashLen); err != nil {
+			return 0, err
+		}
+
+		switch c := bytes.Compare(name, id); {
+		case c < 0:
+			lo = mid + 1
+		case c > 0:
+			hi = mid
+		default:
+			// Names are followed by CRCs, offsets, then large offsets.
+			offsetsStart := namesStart + count*hashLen + count*4
+
+			buf := make([]byte, 8)
+			if _, err := f.ReadAt(buf[:4], offsetsStart+mid*4); err != nil {
+				return 0, err
+			}
+
+			offset := binary.BigEndian.Uint32(buf)
+			if offset&0x80000000 == 0 {
+				return int64(offset), nil
+			}
+
+			large := int64(offset & 0x7fffffff)
+			if _, err := f.ReadAt(buf, offsetsStart+count*4+large*8); err != nil {
+				return 0, err
+			}
+
+			return int64(binary.BigEndian.Uint64(buf)), nil
+		}
+	}
+
+	return 0, ErrNotFound
+}
+
+// readPackObject returns the type and the content of the object at offset in a pack, resolving deltas.
+// Base objects are named with hashes of hashLen bytes: 20 for SHA-1 repositories, 32 for SHA-256 ones.
+func readPackObject(dir, pack string, offset int64, hashLen, depth int) (string, []byte, error) {
+	if depth > maxDeltaDepth {
+		return "", nil, errors.New("delta chain too long")
+	}
+
+	f, err := os.Open(pack)
+	if err != nil {
+		return "", nil, err
+	}
+
+	defer f.Close()
+
+	r := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62))
+
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+
+	typ := (c >> 4) & 7
+
+	// The size is not needed, since the content is zlib compressed.
+	for c&0x80 != 0 {
+		if c, err = r.ReadByte(); err != nil {
+			return "", nil, err
+		}
+	}
+
+	var baseType string
+	var base []byte
+
+	switch typ {
+	case objCommit, objTree, objBlob, objTag:
+		data, err := inflate(r)
+		return typeNames[typ], data, err
+	case objOfsDelta:
+		// Offset of the base, relative to this object.
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", nil, err
+		}
+
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return "", nil, err
+			}
+
+			rel = (rel+1)<<7 | int64(c&0x7f)
+		}
+
+		baseType, base, err = readPackObject(dir, pack, offset-rel, hashLen, depth+1)
+		if err != nil {
+			return "", nil, err
+		}
+	case objRefDelta:
+		id := make([]byte, hashLen)
+		if _, err := io.ReadFull(r, id); err != nil {
+			return "", nil, err
+		}
+
+		baseType, base, err = readObject(dir, hex.EncodeToString(id), depth+1)
+		if err != nil {
+			return "", nil, err
+		}
+	default:
+		return "", nil, fmt.Errorf("invalid object type %d in %s", typ, pack)
+	}
+
+	delta, err := inflate(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := applyDelta(base, delta)
+	return baseType, data, err
+}
+
+func inflate(r io.Reader) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
+var errInvalidDelta = errors.New("invalid delta")
+
+// applyDelta rebuilds an object from its base and a delta,
+// made of instructions copying ranges of the base or inserting new data.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	r := bytes.NewReader(delta)
+
+	// Sizes of the base and of the result.
+	baseSize, err := binary.ReadUvarint(r)
+	if err != nil || baseSize != uint64(len(base)) {
+		return nil, errInvalidDelta
+	}
+
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, errInvalidDelta
+	}
+
+	// Each instruction takes at least a byte, and copies at most 0x10000 bytes,
+	// so a larger size is corrupt and must not be allocated.
+	if size > uint64(len(delta))*0x10000 {
+		return nil, errInvalidDelta
+	}
+
+	out := make([]byte, 0, size)
+
+	for {
+		op, err := r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+
+		switch {
+		case op&0x80 != 0:
+			// The bits of op tell which bytes of the offset and of the size follow.
+			var offset, n uint32
+
+			for i := range 7 {
+				if op&(1<<i) == 0 {
+					continue
+				}
+
+				b, err := r.ReadByte()
+				if err != nil {
+					return nil, errInvalidDelta
+				}
+
+				if i < 4 {
+					offset |= uint32(b) << (8 * i)
+				} else {
+					n |= uint32(b) << (8 * (i - 4))
+				}
+			}
+
+			if n == 0 {
+				n = 0x10000
+			}
+
+			if uint64(offset)+uint64(n) > uint64(len(base)) {
+				return nil, errInvalidDelta
+			}
+
+			out = append(out, base[offset:offset+n]...)
+		case op != 0:
+			data := make([]byte, op)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return nil, errInvalidDelta
+			}
+
+			out = append(out, data...)
+		default:
+			return nil, errInvalidDelta
+		}
+
+		if uint64(len(out)) > size {
+			return nil, errInvalidDelta
+		}
+	}
+
+	if uint64(len(out)) != size {
+		return nil, errInvalidDelta
+	}
+
+	return out, nil
+}
@@ -0,0 +1,58 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello, world")
+
+	// Sizes of the base and of the result, then a copy of "hello", an insert of " gsp," and a copy of "d".
+	delta := []byte{12, 11, 0x90, 5, 5, ' ', 'g', 's', 'p', ',', 0x91, 11, 1}
+
+	out, err := applyDelta(base, delta)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello gsp,d", string(out))
+
+	tests := []struct {
+		name  string
+		delta []byte
+	}{
+		{name: "Copy out of the base", delta: []byte{12, 5, 0x91, 10, 5}},
+		{name: "Base size mismatch", delta: []byte{13, 5, 0x90, 5}},
+		{name: "Result size mismatch", delta: []byte{12, 6, 0x90, 5}},
+		{name: "Result larger than its size", delta: []byte{12, 4, 0x90, 5}},
+		// A result size of 2^63, which cannot be produced by so few instructions.
+		{name: "Corrupt size", delta: []byte{12, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01, 0x90, 5}},
+		{name: "Truncated insert", delta: []byte{12, 5, 5, 'h'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := applyDelta(base, tt.delta)
+			assert.ErrorIs(t, err, errInvalidDelta)
+		})
+	}
+}
+
+func TestReadObjectRefDelta(t *testing.T) {
+	for _, format := range []string{"sha1", "sha256"} {
+		t.Run(format, func(t *testing.T) {
+			dir := newRepo(t, "2024-06-01T12:30:00Z", "--object-format="+format)
+
+			// Bases of deltas are named by their hash, instead of their offset in the pack.
+			gitCmd(t, dir, "-c", "repack.useDeltaBaseOffset=false", "gc", "-q", "--aggressive")
+
+			for _, rev := range []string{"HEAD:README", "HEAD~1:README"} {
+				typ, data, err := readObject(filepath.Join(dir, ".git"), gitCmd(t, dir, "rev-parse", rev), 0)
+				assert.NoError(t, err)
+				assert.Equal(t, "blob", typ)
+				assert.Equal(t, gitCmd(t, dir, "cat-file", "blob", rev), strings.TrimSpace(string(data)))
+			}
+		})
+	}
+}
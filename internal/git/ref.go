@@ -0,0 +1,83 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrRefNotFound = errors.New("reference not found")
+
+// Maximum number of symbolic references followed.
+const maxSymrefDepth = 5
+
+// commonDir returns the directory holding the objects and the shared references of gitDir,
+// which differs from gitDir in worktrees.
+func commonDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+
+	dir := string(bytes.TrimSpace(data))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+
+	return dir
+}
+
+// ResolveRef returns the commit hash a reference (e.g. "refs/heads/main") points to,
+// reading the loose references, then "packed-refs".
+func ResolveRef(gitDir, ref string) (string, error) {
+	return resolveRef(gitDir, ref, 0)
+}
+
+func resolveRef(gitDir, ref string, depth int) (string, error) {
+	if depth > maxSymrefDepth {
+		return "", ErrRefNotFound
+	}
+
+	common := commonDir(gitDir)
+
+	// References specific to a worktree (e.g. "HEAD") are in its own directory.
+	for _, dir := range []string{gitDir, common} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err != nil {
+			continue
+		}
+
+		value := string(bytes.TrimSpace(data))
+
+		if target, ok := strings.CutPrefix(value, "ref: "); ok {
+			return resolveRef(gitDir, target, depth+1)
+		}
+
+		return value, nil
+	}
+
+	f, err := os.Open(filepath.Join(common, "packed-refs"))
+	if err != nil {
+		return "", ErrRefNotFound
+	}
+
+	defer f.Close()
+
+	// "<hash> <ref>" lines, with comments and peeled tags ("^<hash>") in between.
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hash, name, ok := strings.Cut(scanner.Text(), " ")
+		if ok && name == ref {
+			return hash, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", ErrRefNotFound
+}
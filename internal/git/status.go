@@ -0,0 +1,109 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Info summarizes the state of a repository.
+type Info struct {
+	// Branch checked out. Empty when HEAD is detached.
+	Branch string
+
+	// Hash of the commit checked out, and its committer date. Empty before the first commit.
+	Commit     string
+	CommitTime time.Time
+
+	// Whether tracked files have uncommitted changes. Untracked files are not considered.
+	Dirty bool
+
+	// Number of commits the branch is ahead and behind its upstream.
+	Ahead  int
+	Behind int
+}
+
+// Read returns the state of the repository rooted at dir, or [ErrNotRepository].
+//
+// The branch and the last commit are read from the files of the repository (see [ReadHead]). The dirty
+// status and the distance to the upstream branch require running git; when it fails, they are left empty.
+// Reading them from the files would mean comparing the index with the worktree and walking the history,
+// which git does faster and more reliably (e.g. with filesystem monitors, split indexes or shallow clones).
+// Untracked files are not listed, so large worktrees are not scanned.
+func Read(ctx context.Context, dir string) (*Info, error) {
+	info, err := ReadHead(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = readStatus(ctx, dir, info)
+
+	return info, nil
+}
+
+// ReadHead returns the branch and the last commit of the repository rooted at dir, without running git.
+func ReadHead(dir string) (*Info, error) {
+	gitDir, err := Dir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	branch, hash, err := Head(gitDir)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{Branch: branch, Commit: hash}
+
+	if branch != "" {
+		// Not found on new repositories, without commits.
+		info.Commit, err = ResolveRef(gitDir, "refs/heads/"+branch)
+		if err != nil && !errors.Is(err, ErrRefNotFound) {
+			return nil, err
+		}
+	}
+
+	if info.Commit != "" {
+		// Objects may be stored elsewhere (e.g. with alternates), so the date is best effort.
+		info.CommitTime, _ = CommitTime(gitDir, info.Commit)
+	}
+
+	return info, nil
+}
+
+// readStatus sets the dirty status and the distance to the upstream branch of info.
+func readStatus(ctx context.Context, dir string, info *Info) error {
+	// Optional locks are skipped, so git commands run by the user are not blocked.
+	cmd := exec.CommandContext(ctx, "git", "--no-optional-locks", "-C", dir, "status", "--porcelain=v2", "--branch", "--untracked-files=no")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// "# branch.ab +<ahead> -<behind>"
+		if ab, ok := strings.CutPrefix(line, "# branch.ab "); ok {
+			ahead, behind, _ := strings.Cut(ab, " ")
+			info.Ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+			info.Behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+
+			continue
+		}
+
+		// Other lines are changed files.
+		if !strings.HasPrefix(line, "#") {
+			info.Dirty = true
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,150 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gitCmd runs git in dir, with a fixed identity, returning its output.
+func gitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_NAME=gsp",
+		"GIT_AUTHOR_EMAIL=gsp@example.com",
+		"GIT_COMMITTER_NAME=gsp",
+		"GIT_COMMITTER_EMAIL=gsp@example.com",
+	)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %s", strings.Join(args, " "), out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// newRepo creates a repository with two commits on "main", committed at date.
+// Extra arguments are passed to "git init".
+func newRepo(t *testing.T, date string, initArgs ...string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("GIT_COMMITTER_DATE", date)
+
+	dir := t.TempDir()
+	gitCmd(t, dir, append([]string{"init", "-q", "-b", "main"}, initArgs...)...)
+
+	// Similar contents, so packs store one version as a delta of the other.
+	content := strings.Repeat("line of the readme\n", 200)
+
+	for i, extra := range []string{"", "last line\n"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte(content+extra), 0644))
+		gitCmd(t, dir, "add", ".")
+		gitCmd(t, dir, "commit", "-q", "-m", "commit "+strconv.Itoa(i))
+	}
+
+	return dir
+}
+
+func TestRead(t *testing.T) {
+	date := "2024-06-01T12:30:00Z"
+	dir := newRepo(t, date)
+	expectedTime, _ := time.Parse(time.RFC3339, date)
+	head := gitCmd(t, dir, "rev-parse", "HEAD")
+
+	check := func(t *testing.T, dir string) *Info {
+		t.Helper()
+
+		info, err := Read(context.Background(), dir)
+		assert.NoError(t, err)
+		assert.Equal(t, head, info.Commit)
+		assert.True(t, expectedTime.Equal(info.CommitTime), info.CommitTime)
+
+		return info
+	}
+
+	t.Run("Loose objects", func(t *testing.T) {
+		info := check(t, dir)
+		assert.Equal(t, "main", info.Branch)
+		assert.False(t, info.Dirty)
+	})
+
+	t.Run("Packed objects", func(t *testing.T) {
+		gitCmd(t, dir, "gc", "-q", "--aggressive")
+
+		_, err := os.Stat(filepath.Join(dir, ".git", "refs", "heads", "main"))
+		assert.ErrorIs(t, err, os.ErrNotExist, "refs are expected to be packed")
+
+		check(t, dir)
+
+		// The README blobs are stored as deltas of each other.
+		for _, rev := range []string{"HEAD:README", "HEAD~1:README"} {
+			typ, data, err := readObject(filepath.Join(dir, ".git"), gitCmd(t, dir, "rev-parse", rev), 0)
+			assert.NoError(t, err)
+			assert.Equal(t, "blob", typ)
+			assert.Equal(t, gitCmd(t, dir, "cat-file", "blob", rev), strings.TrimSpace(string(data)))
+		}
+	})
+
+	t.Run("Worktree", func(t *testing.T) {
+		worktree := filepath.Join(t.TempDir(), "wt")
+		gitCmd(t, dir, "worktree", "add", "-q", "--detach", worktree)
+
+		info := check(t, worktree)
+		assert.Empty(t, info.Branch)
+	})
+
+	t.Run("Dirty, ahead and behind", func(t *testing.T) {
+		clone := filepath.Join(t.TempDir(), "clone")
+		gitCmd(t, dir, "clone", "-q", dir, clone)
+
+		gitCmd(t, clone, "reset", "-q", "--hard", "HEAD~1")
+		assert.NoError(t, os.WriteFile(filepath.Join(clone, "new"), []byte("new\n"), 0644))
+		gitCmd(t, clone, "add", "new")
+		gitCmd(t, clone, "commit", "-q", "-m", "local")
+
+		// Untracked files do not make the worktree dirty.
+		assert.NoError(t, os.WriteFile(filepath.Join(clone, "untracked"), nil, 0644))
+
+		info, err := Read(context.Background(), clone)
+		assert.NoError(t, err)
+		assert.False(t, info.Dirty)
+		assert.Equal(t, 1, info.Ahead)
+		assert.Equal(t, 1, info.Behind)
+
+		assert.NoError(t, os.WriteFile(filepath.Join(clone, "new"), []byte("changed\n"), 0644))
+
+		info, err = Read(context.Background(), clone)
+		assert.NoError(t, err)
+		assert.True(t, info.Dirty)
+	})
+
+	t.Run("No commits", func(t *testing.T) {
+		empty := t.TempDir()
+		gitCmd(t, empty, "init", "-q", "-b", "main")
+
+		info, err := Read(context.Background(), empty)
+		assert.NoError(t, err)
+		assert.Equal(t, &Info{Branch: "main"}, info)
+	})
+
+	t.Run("Not a repository", func(t *testing.T) {
+		_, err := Read(context.Background(), t.TempDir())
+		assert.ErrorIs(t, err, ErrNotRepository)
+	})
+}
@@ -0,0 +1,235 @@
+// Package history records selected projects to rank them by frecency (frequency and recency).
+package history
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+)
+
+// Once the sum of all ranks exceeds this value, ranks are aged, so old entries fade away.
+const maxRank = 1000
+
+// Entry is a selected path.
+type Entry struct {
+	Path string
+
+	// Incremented on every selection, and decreased when aging.
+	Rank float64
+
+	LastAccess time.Time
+}
+
+// History is a set of selected paths, stored in a text file.
+// It is safe for concurrent use.
+type History struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]*Entry
+
+	// Selections added since the history was loaded or saved,
+	// applied again on top of the file when saving, so selections of other runs are kept.
+	added []selection
+}
+
+// selection is a call to [History.Add].
+type selection struct {
+	path string
+	at   time.Time
+}
+
+// Path returns the history file: "$XDG_DATA_HOME/gsp/history", or "~/.local/share/gsp/history".
+func Path() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, "gsp", "history"), nil
+	}
+
+	return homedir.Expand("~/.local/share/gsp/history")
+}
+
+// Load reads the history file at path. A missing file results in an empty history.
+// On error, an empty history is returned along with it, so it can still be used and saved.
+func Load(path string) (*History, error) {
+	h := &History{path: path, entries: make(map[string]*Entry)}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+
+	if err != nil {
+		return h, err
+	}
+
+	defer file.Close()
+
+	sc := bufio.NewScanner(file)
+	for line := 1; sc.Scan(); line++ {
+		e, err := parseEntry(sc.Text())
+		if err != nil {
+			clear(h.entries)
+			return h, fmt.Errorf("invalid history %q on line %d: %w", path, line, err)
+		}
+
+		h.entries[e.Path] = e
+	}
+
+	if err := sc.Err(); err != nil {
+		clear(h.entries)
+		return h, err
+	}
+
+	return h, nil
+}
+
+// Each line is formatted as "<rank>\t<last access (unix seconds)>\t<path>".
+func parseEntry(line string) (*Entry, error) {
+	fields := strings.SplitN(line, "\t", 3)
+	if len(fields) != 3 {
+		return nil, errors.New("invalid entry")
+	}
+
+	rank, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	lastAccess, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Entry{Path: fields[2], Rank: rank, LastAccess: time.Unix(lastAccess, 0)}, nil
+}
+
+// Add records a selection of p.
+// Paths containing newlines are ignored, since entries are saved one per line.
+func (h *History) Add(p string, now time.Time) {
+	if strings.ContainsAny(p, "\n\r") {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	add(h.entries, p, now)
+	h.added = append(h.added, selection{path: p, at: now})
+}
+
+// add increments the rank of p in entries.
+func add(entries map[string]*Entry, p string, now time.Time) {
+	e, ok := entries[p]
+	if !ok {
+		e = &Entry{Path: p}
+		entries[p] = e
+	}
+
+	e.Rank++
+	e.LastAccess = now
+
+	age(entries)
+}
+
+// age decreases all ranks once their sum exceeds maxRank, dropping the entries that fall below one.
+func age(entries map[string]*Entry) {
+	var total float64
+	for _, e := range entries {
+		total += e.Rank
+	}
+
+	if total <= maxRank {
+		return
+	}
+
+	for p, e := range entries {
+		e.Rank *= 0.9
+
+		if e.Rank < 1 {
+			delete(entries, p)
+		}
+	}
+}
+
+// Score returns the frecency of p, or zero if it was never selected.
+// Like zoxide, the rank is weighted by how long ago p was last selected.
+func (h *History) Score(p string, now time.Time) float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	e, ok := h.entries[p]
+	if !ok {
+		return 0
+	}
+
+	switch since := now.Sub(e.LastAccess); {
+	case since < time.Hour:
+		return e.Rank * 4
+	case since < 24*time.Hour:
+		return e.Rank * 2
+	case since < 7*24*time.Hour:
+		return e.Rank / 2
+	default:
+		return e.Rank / 4
+	}
+}
+
+// Save writes the history to its file, replacing it atomically.
+// The file is read again first, and the selections added since are applied on top of it,
+// so concurrent runs do not lose each other's selections.
+func (h *History) Save() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+
+	// An unreadable file is replaced by the loaded history.
+	entries := h.entries
+	if saved, err := Load(h.path); err == nil {
+		entries = saved.entries
+		for _, s := range h.added {
+			add(entries, s.path, s.at)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", strconv.FormatFloat(e.Rank, 'f', -1, 64), e.LastAccess.Unix(), e.Path)
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return err
+	}
+
+	h.entries = entries
+	h.added = nil
+
+	return nil
+}
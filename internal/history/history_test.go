@@ -0,0 +1,92 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gsp", "history")
+	now := time.Unix(1_700_000_000, 0)
+
+	h, err := Load(path)
+	assert.NoError(t, err)
+
+	h.Add("/src/often", now.Add(-2*time.Hour))
+	h.Add("/src/often", now.Add(-2*time.Hour))
+	h.Add("/src/often", now.Add(-2*time.Hour))
+	h.Add("/src/recent", now.Add(-time.Minute))
+	h.Add("/src/old", now.Add(-30*24*time.Hour))
+	h.Add("/src/odd\nname", now)
+
+	assert.Equal(t, float64(6), h.Score("/src/often", now))
+	assert.Equal(t, float64(4), h.Score("/src/recent", now))
+	assert.Equal(t, 0.25, h.Score("/src/old", now))
+	assert.Equal(t, float64(0), h.Score("/src/never", now))
+	assert.Equal(t, float64(0), h.Score("/src/odd\nname", now))
+
+	assert.NoError(t, h.Save())
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, h.entries, loaded.entries)
+}
+
+func TestHistory_Aging(t *testing.T) {
+	h, err := Load(filepath.Join(t.TempDir(), "history"))
+	assert.NoError(t, err)
+
+	now := time.Now()
+	h.Add("/src/once", now)
+
+	for i := 0; i < maxRank; i++ {
+		h.Add("/src/always", now)
+	}
+
+	assert.NotContains(t, h.entries, "/src/once")
+	assert.Less(t, h.entries["/src/always"].Rank, float64(maxRank))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	assert.NoError(t, os.WriteFile(path, []byte("invalid\n"), 0644))
+
+	h, err := Load(path)
+	assert.Error(t, err)
+	assert.Empty(t, h.entries)
+}
+
+func TestSave_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	now := time.Now()
+
+	// Two runs load the same history, and each saves its own selection.
+	first, err := Load(path)
+	assert.NoError(t, err)
+
+	second, err := Load(path)
+	assert.NoError(t, err)
+
+	first.Add("/src/first", now)
+	second.Add("/src/second", now)
+	second.Add("/src/second", now)
+
+	assert.NoError(t, first.Save())
+	assert.NoError(t, second.Save())
+
+	loaded, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), loaded.entries["/src/first"].Rank)
+	assert.Equal(t, float64(2), loaded.entries["/src/second"].Rank)
+
+	// Selections are only applied once, when saving again.
+	assert.NoError(t, second.Save())
+
+	loaded, err = Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(2), loaded.entries["/src/second"].Rank)
+}
@@ -0,0 +1,189 @@
+// Package ignore implements gitignore-style pattern matching for directory walks.
+package ignore
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type pattern struct {
+	// Directory the pattern is relative to.
+	base string
+
+	// Pattern split by "/". Unanchored patterns start with "**".
+	segments []string
+
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher matches paths against an ordered list of gitignore-style patterns.
+// The last matching pattern decides the result, so negated patterns ("!pattern")
+// can re-include paths excluded by previous ones.
+//
+// A nil Matcher matches nothing.
+type Matcher struct {
+	patterns []pattern
+}
+
+// Append returns a new Matcher with the patterns in lines added after the existing ones.
+// Patterns are relative to base. Empty lines and comments are ignored.
+//
+// The receiver is never modified, which allows matchers to be stacked while walking.
+func (m *Matcher) Append(base string, lines []string) (*Matcher, error) {
+	var patterns []pattern
+
+	if m != nil {
+		// Force a copy on append, so siblings never share the same backing array.
+		patterns = m.patterns[:len(m.patterns):len(m.patterns)]
+	}
+
+	for _, line := range lines {
+		p, ok, err := parse(base, line)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			patterns = append(patterns, p)
+		}
+	}
+
+	return &Matcher{patterns: patterns}, nil
+}
+
+// AppendFile is like [Matcher.Append], but reads the patterns from the contents of an ignore file.
+// As with git, invalid patterns are silently dropped.
+func (m *Matcher) AppendFile(base string, data []byte) *Matcher {
+	var patterns []pattern
+
+	if m != nil {
+		patterns = m.patterns[:len(m.patterns):len(m.patterns)]
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if p, ok, err := parse(base, line); ok && err == nil {
+			patterns = append(patterns, p)
+		}
+	}
+
+	return &Matcher{patterns: patterns}
+}
+
+// Match reports whether the path p is ignored.
+func (m *Matcher) Match(p string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+
+	ignored := false
+
+	for _, pt := range m.patterns {
+		if pt.dirOnly && !isDir {
+			continue
+		}
+
+		rel, ok := relative(pt.base, p)
+		if !ok {
+			continue
+		}
+
+		if matchSegments(pt.segments, strings.Split(rel, "/")) {
+			ignored = !pt.negate
+		}
+	}
+
+	return ignored
+}
+
+func parse(base, line string) (pattern, bool, error) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return pattern{}, false, nil
+	}
+
+	p := pattern{base: filepath.Clean(base)}
+
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if line == "" {
+		return pattern{}, false, nil
+	}
+
+	// A separator at the beginning or middle anchors the pattern to its base.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	p.segments = strings.Split(line, "/")
+	if !anchored {
+		p.segments = append([]string{"**"}, p.segments...)
+	}
+
+	for _, seg := range p.segments {
+		if _, err := path.Match(seg, ""); err != nil {
+			return pattern{}, false, fmt.Errorf("invalid pattern %q: %w", line, err)
+		}
+	}
+
+	return p, true, nil
+}
+
+// relative returns p relative to base using "/" as separator.
+// It fails when p is not inside base.
+func relative(base, p string) (string, bool) {
+	if !strings.HasPrefix(p, base) {
+		return "", false
+	}
+
+	rel := p[len(base):]
+	if rel == "" || (rel[0] != filepath.Separator && !strings.HasSuffix(base, string(filepath.Separator))) {
+		return "", false
+	}
+
+	return filepath.ToSlash(strings.TrimPrefix(rel, string(filepath.Separator))), true
+}
+
+func matchSegments(pat, parts []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			pat = pat[1:]
+
+			// A trailing "**" matches everything inside, but not the directory itself.
+			if len(pat) == 0 {
+				return len(parts) > 0
+			}
+
+			for i := range parts {
+				if matchSegments(pat, parts[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(parts) == 0 {
+			return false
+		}
+
+		if ok, _ := path.Match(pat[0], parts[0]); !ok {
+			return false
+		}
+
+		pat, parts = pat[1:], parts[1:]
+	}
+
+	return len(parts) == 0
+}
@@ -0,0 +1,86 @@
+package ignore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"Basename at root", []string{"node_modules"}, "/base/node_modules", true, true},
+		{"Basename nested", []string{"node_modules"}, "/base/a/b/node_modules", true, true},
+		{"Basename no match", []string{"node_modules"}, "/base/modules", true, false},
+		{"Wildcard", []string{"*.cache"}, "/base/a/.build.cache", true, true},
+		{"Directory only", []string{"target/"}, "/base/a/target", true, true},
+		{"Directory only on file", []string{"target/"}, "/base/a/target", false, false},
+		{"Anchored", []string{"/build"}, "/base/build", true, true},
+		{"Anchored nested", []string{"/build"}, "/base/a/build", true, false},
+		{"Middle separator is anchored", []string{"a/build"}, "/base/b/a/build", true, false},
+		{"Double star prefix", []string{"**/dist"}, "/base/a/b/dist", true, true},
+		{"Double star middle", []string{"a/**/dist"}, "/base/a/b/c/dist", true, true},
+		{"Double star middle zero dirs", []string{"a/**/dist"}, "/base/a/dist", true, true},
+		{"Double star suffix", []string{"vendor/**"}, "/base/vendor/pkg", true, true},
+		{"Double star suffix self", []string{"vendor/**"}, "/base/vendor", true, false},
+		{"Negation", []string{"build*", "!build-tools"}, "/base/build-tools", true, false},
+		{"Negation order", []string{"!build-tools", "build*"}, "/base/build-tools", true, true},
+		{"Comment", []string{"# build"}, "/base/build", true, false},
+		{"Escaped hash", []string{`\#build`}, "/base/#build", true, true},
+		{"Outside base", []string{"build"}, "/other/build", true, false},
+		{"Base itself", []string{"base"}, "/base", true, false},
+		{"Base prefix", []string{"build"}, "/base2/build", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := new(Matcher).Append("/base", tt.lines)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, m.Match(tt.path, tt.isDir))
+		})
+	}
+}
+
+func TestMatcher_Append(t *testing.T) {
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := new(Matcher).Append("/base", []string{"[a-"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Stacking", func(t *testing.T) {
+		parent, err := new(Matcher).Append("/base", []string{"build"})
+		assert.NoError(t, err)
+
+		child, err := parent.Append("/base/a", []string{"!build"})
+		assert.NoError(t, err)
+
+		sibling, err := parent.Append("/base/b", []string{"dist"})
+		assert.NoError(t, err)
+
+		assert.True(t, parent.Match("/base/a/build", true))
+		assert.False(t, child.Match("/base/a/build", true))
+		assert.True(t, child.Match("/base/c/build", true))
+		assert.True(t, sibling.Match("/base/b/build", true))
+		assert.True(t, sibling.Match("/base/b/dist", true))
+		assert.False(t, sibling.Match("/base/a/dist", true))
+	})
+
+	t.Run("File", func(t *testing.T) {
+		data := []byte("# comment\n\nbuild/\n[a-\n!build/keep\n")
+		m := new(Matcher).AppendFile("/base", data)
+
+		assert.True(t, m.Match("/base/build", true))
+		assert.True(t, m.Match("/base/a/build", true))
+		assert.False(t, m.Match("/base/build/keep", true))
+	})
+
+	t.Run("Nil matcher", func(t *testing.T) {
+		var m *Matcher
+		assert.False(t, m.Match("/base/build", true))
+	})
+}
@@ -0,0 +1,192 @@
+// Package preview summarizes a project for the preview pane of the selectors.
+package preview
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gabefiori/gsp/internal/git"
+)
+
+// Number of README lines included in the summary.
+const readmeLines = 20
+
+// Languages detected by the presence of a file in the project root.
+var languageMarkers = map[string]string{
+	"go.mod":           "Go",
+	"Cargo.toml":       "Rust",
+	"package.json":     "JavaScript",
+	"tsconfig.json":    "TypeScript",
+	"pyproject.toml":   "Python",
+	"setup.py":         "Python",
+	"requirements.txt": "Python",
+	"Gemfile":          "Ruby",
+	"pom.xml":          "Java",
+	"build.gradle":     "Java",
+	"build.gradle.kts": "Kotlin",
+	"composer.json":    "PHP",
+	"mix.exs":          "Elixir",
+	"Package.swift":    "Swift",
+	"pubspec.yaml":     "Dart",
+	"CMakeLists.txt":   "C/C++",
+	"build.zig":        "Zig",
+	"deno.json":        "TypeScript",
+}
+
+// Languages detected by the extension of the files in the project root.
+var languageExts = map[string]string{
+	".go":     "Go",
+	".rs":     "Rust",
+	".js":     "JavaScript",
+	".ts":     "TypeScript",
+	".py":     "Python",
+	".rb":     "Ruby",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".php":    "PHP",
+	".ex":     "Elixir",
+	".swift":  "Swift",
+	".dart":   "Dart",
+	".c":      "C/C++",
+	".cpp":    "C/C++",
+	".cs":     "C#",
+	".csproj": "C#",
+	".lua":    "Lua",
+	".zig":    "Zig",
+	".sh":     "Shell",
+	".nix":    "Nix",
+}
+
+// Write writes a summary of the project in dir to w: git branch, last commit,
+// dirty status, detected languages and the head of the README.
+//
+// Git details are only included when dir is a repository.
+// Failing to run git is not an error, since the summary is best effort.
+func Write(ctx context.Context, w io.Writer, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "%s\n\n", dir)
+
+	if gitDir, err := git.Dir(dir); err == nil {
+		writeGit(ctx, buf, dir, gitDir)
+	}
+
+	if langs := languages(entries); len(langs) > 0 {
+		fmt.Fprintf(buf, "Languages: %s\n", strings.Join(langs, ", "))
+	}
+
+	if err := writeReadme(buf, dir, entries); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, buf)
+	return err
+}
+
+func writeGit(ctx context.Context, w io.Writer, dir, gitDir string) {
+	branch, hash, err := git.Head(gitDir)
+	if err == nil {
+		if branch == "" {
+			branch = fmt.Sprintf("(detached at %.7s)", hash)
+		}
+
+		fmt.Fprintf(w, "Branch:    %s\n", branch)
+	}
+
+	if commit, err := runGit(ctx, dir, "log", "-1", "--format=%h %s (%cr)"); err == nil && commit != "" {
+		fmt.Fprintf(w, "Commit:    %s\n", commit)
+	}
+
+	status, err := runGit(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return
+	}
+
+	if status == "" {
+		fmt.Fprintln(w, "Status:    clean")
+		return
+	}
+
+	fmt.Fprintf(w, "Status:    %d changed files\n", strings.Count(status, "\n")+1)
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// languages detects the languages of a project from the files in its root.
+func languages(entries []os.DirEntry) []string {
+	var langs []string
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		lang, ok := languageMarkers[entry.Name()]
+		if !ok {
+			lang, ok = languageExts[filepath.Ext(entry.Name())]
+		}
+
+		if ok && !slices.Contains(langs, lang) {
+			langs = append(langs, lang)
+		}
+	}
+
+	slices.Sort(langs)
+
+	return langs
+}
+
+// writeReadme writes the first lines of the README in dir, if any.
+func writeReadme(w io.Writer, dir string, entries []os.DirEntry) error {
+	var name string
+
+	for _, entry := range entries {
+		base := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if !entry.IsDir() && strings.EqualFold(base, "readme") {
+			name = entry.Name()
+			break
+		}
+	}
+
+	if name == "" {
+		return nil
+	}
+
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	fmt.Fprintf(w, "\n%s\n\n", name)
+
+	sc := bufio.NewScanner(f)
+	for i := 0; i < readmeLines && sc.Scan(); i++ {
+		fmt.Fprintln(w, sc.Text())
+	}
+
+	return sc.Err()
+}
@@ -0,0 +1,68 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	// Without git, only the details read from files are written.
+	t.Setenv("PATH", t.TempDir())
+
+	tempDir := t.TempDir()
+
+	repo := filepath.Join(tempDir, "repo")
+	plain := filepath.Join(tempDir, "plain")
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0755))
+	assert.NoError(t, os.Mkdir(plain, 0755))
+
+	files := map[string]string{
+		filepath.Join(repo, ".git", "HEAD"): "ref: refs/heads/main\n",
+		filepath.Join(repo, "go.mod"):       "module repo\n",
+		filepath.Join(repo, "build.sh"):     "#!/bin/sh\n",
+		filepath.Join(repo, "README.md"):    "# repo\n\nA project.\n",
+		filepath.Join(plain, "notes.txt"):   "notes\n",
+	}
+
+	for name, data := range files {
+		assert.NoError(t, os.WriteFile(name, []byte(data), 0644))
+	}
+
+	tests := []struct {
+		dir      string
+		expected string
+	}{
+		{
+			dir: repo,
+			expected: repo + "\n\n" +
+				"Branch:    main\n" +
+				"Languages: Go, Shell\n" +
+				"\nREADME.md\n\n" +
+				"# repo\n\nA project.\n",
+		},
+		{
+			dir:      plain,
+			expected: plain + "\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.dir), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+
+			assert.NoError(t, Write(context.Background(), buf, tt.dir))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestWriteMissingDir(t *testing.T) {
+	err := Write(context.Background(), new(bytes.Buffer), filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
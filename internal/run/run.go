@@ -0,0 +1,70 @@
+// Package run runs commands in project directories.
+package run
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+)
+
+// Command runs args in dir, attached to the terminal, and waits for it to exit.
+//
+// Interrupts from the terminal (e.g. ctrl-c) reach the command directly, since it belongs to the
+// same process group. Termination signals sent to gsp (e.g. SIGTERM, SIGHUP) are forwarded to it.
+func Command(dir string, args []string, stdout io.Writer) error {
+	if len(args) == 0 || args[0] == "" {
+		return errors.New("empty command")
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	// Registered before starting, so gsp is not terminated before the command.
+	// Without signals, Notify would relay all of them.
+	signals := make(chan os.Signal, 1)
+	if len(forwardedSignals) > 0 {
+		signal.Notify(signals, forwardedSignals...)
+		defer signal.Stop(signals)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = cmd.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return cmd.Wait()
+}
+
+// ExitCode returns the exit code of a command that failed with err, following the shell convention
+// of 128 plus the signal number when the command was killed by a signal.
+// It returns false if the command did not run.
+func ExitCode(err error) (int, bool) {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 0, false
+	}
+
+	if code, ok := signalCode(exitErr); ok {
+		return code, true
+	}
+
+	return exitErr.ExitCode(), true
+}
@@ -0,0 +1,71 @@
+//go:build unix
+
+package run
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, Command(dir, []string{"pwd"}, out))
+	assert.Equal(t, dir+"\n", out.String())
+
+	assert.EqualError(t, Command(dir, nil, io.Discard), "empty command")
+
+	t.Run("Exit code", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			args     []string
+			expected int
+			ran      bool
+		}{
+			{name: "Failed", args: []string{"sh", "-c", "exit 3"}, expected: 3, ran: true},
+			{name: "Killed", args: []string{"sh", "-c", "kill -KILL $$"}, expected: 128 + 9, ran: true},
+			{name: "Not found", args: []string{"gsp-missing-command"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := Command(dir, tt.args, io.Discard)
+				assert.Error(t, err)
+
+				code, ok := ExitCode(err)
+				assert.Equal(t, tt.ran, ok)
+				assert.Equal(t, tt.expected, code)
+			})
+		}
+	})
+
+	t.Run("Forwarded signal", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		errCh := make(chan error, 1)
+
+		go func() {
+			errCh <- Command(dir, []string{"sh", "-c", `trap 'echo terminated; exit 5' TERM; echo ready; while :; do sleep 0.01; done`}, pw)
+			pw.Close()
+		}()
+
+		lines := bufio.NewScanner(pr)
+		assert.True(t, lines.Scan())
+		assert.Equal(t, "ready", lines.Text())
+
+		assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+		assert.True(t, lines.Scan())
+		assert.Equal(t, "terminated", lines.Text())
+
+		code, ok := ExitCode(<-errCh)
+		assert.True(t, ok)
+		assert.Equal(t, 5, code)
+	})
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package run
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Only interrupts can be caught, and they already reach the command.
+var forwardedSignals = []os.Signal{}
+
+func signalCode(err *exec.ExitError) (int, bool) {
+	return 0, false
+}
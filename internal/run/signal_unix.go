@@ -0,0 +1,20 @@
+//go:build unix
+
+package run
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+var forwardedSignals = []os.Signal{syscall.SIGTERM, syscall.SIGHUP}
+
+func signalCode(err *exec.ExitError) (int, bool) {
+	status, ok := err.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return 0, false
+	}
+
+	return 128 + int(status.Signal()), true
+}
@@ -0,0 +1,493 @@
+package selector
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gabefiori/gsp/internal/fuzzy"
+)
+
+// Escape sequences used to draw the interface.
+const (
+	escAltScreenOn  = "\x1b[?1049h"
+	escAltScreenOff = "\x1b[?1049l"
+	escHome         = "\x1b[H"
+	escClearLine    = "\x1b[K"
+	escClearBelow   = "\x1b[J"
+	escReset        = "\x1b[0m"
+	escBold         = "\x1b[1m"
+	escDim          = "\x1b[2m"
+	escMatch        = "\x1b[32m"
+	escMatchOff     = "\x1b[39m"
+)
+
+// Builtin is a fuzzy selector drawn directly on the terminal, without external dependencies.
+type Builtin struct {
+	// Whether several items can be marked with tab.
+	multi bool
+
+	// Whether only the labels of the items are displayed (see [Options.Labeled]).
+	labeled bool
+}
+
+// NewBuiltin creates a builtin selector. Arguments in opts are ignored.
+func NewBuiltin(opts Options) Selector {
+	return &Builtin{multi: opts.Multi, labeled: opts.Labeled}
+}
+
+func (b *Builtin) Run(ctx context.Context, inputChan chan string) ([]string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tty.Close()
+
+	restore, err := makeRaw(tty)
+	if err != nil {
+		return nil, err
+	}
+
+	defer restore()
+
+	size := func() (int, int) {
+		width, height, err := termSize(tty)
+		if err != nil || width == 0 || height == 0 {
+			return 80, 24
+		}
+
+		return width, height
+	}
+
+	u := newUI(tty, tty, size)
+	u.multi = b.multi
+	u.labeled = b.labeled
+
+	return u.run(ctx, inputChan)
+}
+
+// ui is the state of the built-in selector.
+type ui struct {
+	in   io.Reader
+	out  *bufio.Writer
+	size func() (width, height int)
+
+	// Items received so far, and the text displayed for each. Only appended, guarded by mu.
+	items  []string
+	labels []string
+	mu     sync.Mutex
+
+	// Whether only the part of the items after [LabelSep] is displayed and matched.
+	labeled bool
+
+	query   []rune
+	matches []fuzzy.Result
+
+	// Number of items the matches were computed for.
+	matched int
+
+	// Index of the highlighted match, and of the first visible one.
+	cursor int
+	offset int
+
+	// Whether several items can be marked.
+	multi bool
+
+	// Indexes of the marked items, in the order they were marked.
+	marked []int
+}
+
+func newUI(in io.Reader, out io.Writer, size func() (int, int)) *ui {
+	return &ui{in: in, out: bufio.NewWriter(out), size: size}
+}
+
+func (u *ui) run(ctx context.Context, inputChan chan string) ([]string, error) {
+	// Closed on return, so the key reader does not block on keys pressed afterwards.
+	done := make(chan struct{})
+	defer close(done)
+
+	keys := make(chan key)
+	go readKeys(u.in, keys, done)
+
+	// Signaled when new items arrive. Buffered, so multiple items are handled at once.
+	itemsCh := make(chan struct{}, 1)
+	go u.receive(ctx, inputChan, itemsCh)
+
+	u.out.WriteString(escAltScreenOn)
+	defer func() {
+		u.out.WriteString(escAltScreenOff)
+		u.out.Flush()
+	}()
+
+	u.filter()
+	if err := u.draw(); err != nil {
+		return nil, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-itemsCh:
+			u.filterNew()
+		case k, ok := <-keys:
+			// The terminal was closed.
+			if !ok {
+				return nil, nil
+			}
+
+			switch k.code {
+			case keyCancel:
+				return nil, nil
+			case keyEnter:
+				return u.selected(), nil
+			default:
+				u.handle(k)
+			}
+		}
+
+		if err := u.draw(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (u *ui) receive(ctx context.Context, inputChan chan string, itemsCh chan struct{}) {
+	for {
+		select {
+		case item, ok := <-inputChan:
+			if !ok {
+				return
+			}
+
+			label := item
+			if u.labeled {
+				if _, after, ok := strings.Cut(item, LabelSep); ok {
+					label = after
+				}
+			}
+
+			u.mu.Lock()
+			u.items = append(u.items, item)
+			u.labels = append(u.labels, label)
+			u.mu.Unlock()
+
+			select {
+			case itemsCh <- struct{}{}:
+			default:
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (u *ui) snapshot() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.items[:len(u.items):len(u.items)]
+}
+
+func (u *ui) labelsSnapshot() []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.labels[:len(u.labels):len(u.labels)]
+}
+
+// filter matches the query against the labels, keeping the cursor within the matches.
+func (u *ui) filter() {
+	u.matches = nil
+	u.matched = 0
+	u.filterNew()
+}
+
+// filterNew matches the query against the labels received since the last match, merging them into the matches.
+func (u *ui) filterNew() {
+	labels := u.labelsSnapshot()
+	u.matches = fuzzy.RankFrom(string(u.query), labels, u.matches, u.matched)
+	u.matched = len(labels)
+	u.cursor = min(u.cursor, max(len(u.matches)-1, 0))
+}
+
+// selected returns the marked items or, when none are marked, the highlighted one.
+func (u *ui) selected() []string {
+	items := u.snapshot()
+
+	if len(u.marked) > 0 {
+		selected := make([]string, len(u.marked))
+		for i, idx := range u.marked {
+			selected[i] = items[idx]
+		}
+
+		return selected
+	}
+
+	if len(u.matches) == 0 {
+		return nil
+	}
+
+	return []string{items[u.matches[u.cursor].Index]}
+}
+
+// toggle marks or unmarks the highlighted item.
+func (u *ui) toggle() {
+	if !u.multi || len(u.matches) == 0 {
+		return
+	}
+
+	idx := u.matches[u.cursor].Index
+
+	if i := slices.Index(u.marked, idx); i != -1 {
+		u.marked = slices.Delete(u.marked, i, i+1)
+	} else {
+		u.marked = append(u.marked, idx)
+	}
+}
+
+func (u *ui) handle(k key) {
+	switch k.code {
+	case keyUp:
+		u.cursor = max(u.cursor-1, 0)
+		return
+	case keyDown:
+		u.cursor = min(u.cursor+1, max(len(u.matches)-1, 0))
+		return
+	case keyToggle:
+		u.toggle()
+		u.cursor = min(u.cursor+1, max(len(u.matches)-1, 0))
+		return
+	case keyToggleUp:
+		u.toggle()
+		u.cursor = max(u.cursor-1, 0)
+		return
+	case keyRune:
+		u.query = append(u.query, k.r)
+	case keyBackspace:
+		if len(u.query) > 0 {
+			u.query = u.query[:len(u.query)-1]
+		}
+	case keyDeleteWord:
+		end := len(u.query)
+		for end > 0 && u.query[end-1] == ' ' {
+			end--
+		}
+
+		for end > 0 && u.query[end-1] != ' ' {
+			end--
+		}
+
+		u.query = u.query[:end]
+	case keyClear:
+		u.query = u.query[:0]
+	default:
+		return
+	}
+
+	u.cursor = 0
+	u.filter()
+}
+
+// draw renders the prompt, the match counter and the visible matches, from the top of the screen.
+func (u *ui) draw() error {
+	width, height := u.size()
+	rows := max(height-2, 1)
+
+	if u.cursor < u.offset {
+		u.offset = u.cursor
+	} else if u.cursor >= u.offset+rows {
+		u.offset = u.cursor - rows + 1
+	}
+
+	labels := u.labelsSnapshot()
+
+	u.out.WriteString(escHome)
+	u.out.WriteString(truncate("> "+string(u.query), width))
+	u.out.WriteString(escClearLine + "\r\n")
+	u.out.WriteString(escDim + "  " + strconv.Itoa(len(u.matches)) + "/" + strconv.Itoa(u.matched))
+	if u.multi {
+		u.out.WriteString(" (" + strconv.Itoa(len(u.marked)) + ")")
+	}
+
+	u.out.WriteString(escReset)
+	u.out.WriteString(escClearLine)
+
+	for i := u.offset; i < len(u.matches) && i < u.offset+rows; i++ {
+		m := u.matches[i]
+		u.out.WriteString("\r\n")
+
+		if i == u.cursor {
+			u.out.WriteString(escBold + ">")
+		} else {
+			u.out.WriteString(" ")
+		}
+
+		if slices.Contains(u.marked, m.Index) {
+			u.out.WriteString("*")
+		} else {
+			u.out.WriteString(" ")
+		}
+
+		u.writeItem(labels[m.Index], m.Positions, width-2)
+		u.out.WriteString(escReset + escClearLine)
+	}
+
+	u.out.WriteString(escClearBelow)
+
+	// Leave the cursor at the end of the query.
+	col := min(utf8.RuneCountInString("> "+string(u.query)), width-1) + 1
+	u.out.WriteString("\x1b[1;" + strconv.Itoa(col) + "H")
+
+	return u.out.Flush()
+}
+
+// writeItem writes item truncated to width runes, highlighting the runes at positions.
+func (u *ui) writeItem(item string, positions []int, width int) {
+	var i, p int
+
+	for _, r := range item {
+		if i >= width {
+			return
+		}
+
+		// Control characters (e.g. newlines in paths) would break the interface.
+		if unicode.IsControl(r) {
+			r = '?'
+		}
+
+		if p < len(positions) && positions[p] == i {
+			u.out.WriteString(escMatch)
+			u.out.WriteRune(r)
+			u.out.WriteString(escMatchOff)
+			p++
+		} else {
+			u.out.WriteRune(r)
+		}
+
+		i++
+	}
+}
+
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	return string([]rune(s)[:max(width, 0)])
+}
+
+type keyCode int8
+
+const (
+	keyRune keyCode = iota
+	keyEnter
+	keyCancel
+	keyUp
+	keyDown
+	keyBackspace
+	keyDeleteWord
+	keyClear
+	keyToggle
+	keyToggleUp
+)
+
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// readKeys sends the keys read from r, until it fails or done is closed.
+func readKeys(r io.Reader, keys chan<- key, done <-chan struct{}) {
+	defer close(keys)
+
+	buf := make([]byte, 256)
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			for _, k := range parseKeys(buf[:n]) {
+				select {
+				case keys <- k:
+				case <-done:
+					return
+				}
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func parseKeys(b []byte) []key {
+	var keys []key
+
+	for i := 0; i < len(b); {
+		c := b[i]
+
+		switch {
+		case c == 0x1b:
+			// A lone escape, instead of an escape sequence, even when other keys follow in the same read.
+			if i+1 == len(b) || (b[i+1] != '[' && b[i+1] != 'O') {
+				keys = append(keys, key{code: keyCancel})
+				i++
+				continue
+			}
+
+			// Skip to the final byte of the sequence.
+			j := i + 2
+			for j < len(b) && (b[j] < 0x40 || b[j] > 0x7e) {
+				j++
+			}
+
+			if j < len(b) {
+				switch b[j] {
+				case 'A':
+					keys = append(keys, key{code: keyUp})
+				case 'B':
+					keys = append(keys, key{code: keyDown})
+				case 'Z': // shift-tab
+					keys = append(keys, key{code: keyToggleUp})
+				}
+			}
+
+			i = j + 1
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, key{code: keyEnter})
+		case c == 0x03 || c == 0x07: // ctrl-c, ctrl-g
+			keys = append(keys, key{code: keyCancel})
+		case c == 0x10: // ctrl-p
+			keys = append(keys, key{code: keyUp})
+		case c == 0x0e: // ctrl-n
+			keys = append(keys, key{code: keyDown})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, key{code: keyBackspace})
+		case c == 0x17: // ctrl-w
+			keys = append(keys, key{code: keyDeleteWord})
+		case c == 0x15: // ctrl-u
+			keys = append(keys, key{code: keyClear})
+		case c == '\t':
+			keys = append(keys, key{code: keyToggle})
+		case c >= 0x20:
+			r, size := utf8.DecodeRune(b[i:])
+			keys = append(keys, key{code: keyRune, r: r})
+			i += size
+			continue
+		}
+
+		i++
+	}
+
+	return keys
+}
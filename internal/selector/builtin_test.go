@@ -0,0 +1,175 @@
+package selector
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("a\x1b[A\x1b[B\x0e\x10\x7f\x17\x15\t\x1b[Zé\r\x1b[1;5C\x03\x1b"))
+
+	assert.Equal(t, []key{
+		{code: keyRune, r: 'a'},
+		{code: keyUp},
+		{code: keyDown},
+		{code: keyDown},
+		{code: keyUp},
+		{code: keyBackspace},
+		{code: keyDeleteWord},
+		{code: keyClear},
+		{code: keyToggle},
+		{code: keyToggleUp},
+		{code: keyRune, r: 'é'},
+		{code: keyEnter},
+		{code: keyCancel},
+		{code: keyCancel},
+	}, keys)
+
+	// An escape followed by another key is not a sequence.
+	assert.Equal(t, []key{{code: keyCancel}, {code: keyRune, r: 'q'}}, parseKeys([]byte("q")))
+}
+
+func TestReadKeys(t *testing.T) {
+	in, w := io.Pipe()
+	keys := make(chan key)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		_, _ = w.Write([]byte("ab"))
+	}()
+
+	go func() {
+		readKeys(in, keys, done)
+		close(stopped)
+	}()
+
+	assert.Equal(t, key{code: keyRune, r: 'a'}, <-keys)
+
+	// Without a reader, closing done stops the reader instead of blocking it on the next key.
+	close(done)
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("key reader did not stop")
+	}
+}
+
+func TestUI(t *testing.T) {
+	u := newUI(nil, io.Discard, func() (int, int) { return 80, 24 })
+	u.items = []string{"~/src/web", "~/src/rapid", "~/src/api"}
+	u.labels = u.items
+	u.filter()
+
+	assert.Len(t, u.matches, 3)
+	assert.Equal(t, []string{"~/src/web"}, u.selected())
+
+	for _, r := range "api" {
+		u.handle(key{code: keyRune, r: r})
+	}
+
+	assert.Len(t, u.matches, 2)
+	assert.Equal(t, []string{"~/src/api"}, u.selected())
+
+	u.handle(key{code: keyDown})
+	assert.Equal(t, []string{"~/src/rapid"}, u.selected())
+
+	// The cursor does not go past the last match.
+	u.handle(key{code: keyDown})
+	assert.Equal(t, []string{"~/src/rapid"}, u.selected())
+
+	u.handle(key{code: keyDeleteWord})
+	assert.Empty(t, u.query)
+	assert.Equal(t, []string{"~/src/web"}, u.selected())
+
+	u.handle(key{code: keyRune, r: 'x'})
+	assert.Empty(t, u.matches)
+	assert.Empty(t, u.selected())
+	assert.NoError(t, u.draw())
+}
+
+func TestUIRun(t *testing.T) {
+	items := []string{"~/src/web", "~/src/rapid", "~/src/api"}
+
+	run := func(keys string, multi bool) ([]string, error) {
+		return runUI(t, items, keys, func(u *ui) {
+			u.multi = multi
+		})
+	}
+
+	result, err := run("rpd\r", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"~/src/rapid"}, result)
+
+	result, err = run("api\x03", false)
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+
+	// Tab is ignored outside of multi-select mode.
+	result, err = run("\t\t\r", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"~/src/api"}, result)
+
+	// Tab marks api, web and rapid; shift-tab then unmarks api, the last item.
+	result, err = run("\x1b[B\x1b[B\t\x1b[A\x1b[A\t\t\x1b[Z\r", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"~/src/web", "~/src/rapid"}, result)
+
+	t.Run("Labeled", func(t *testing.T) {
+		labeled := []string{"/src/web\tweb", "/src/rapid\trapid", "/src/api\tapi  [work]"}
+
+		// The values are not matched.
+		result, err := runUI(t, labeled, "src\r", func(u *ui) {
+			u.labeled = true
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, result)
+
+		result, err = runUI(t, labeled, "work\r", func(u *ui) {
+			u.labeled = true
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"/src/api\tapi  [work]"}, result)
+	})
+
+	t.Run("Context canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		in, _ := io.Pipe()
+		_, err := newUI(in, io.Discard, func() (int, int) { return 80, 24 }).run(ctx, make(chan string))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// runUI runs the selector with the given items, typing keys once every item is received.
+func runUI(t *testing.T, items []string, keys string, setup func(u *ui)) ([]string, error) {
+	t.Helper()
+
+	in, keysW := io.Pipe()
+	u := newUI(in, io.Discard, func() (int, int) { return 80, 24 })
+	setup(u)
+
+	inputChan := make(chan string, len(items))
+	for _, item := range items {
+		inputChan <- item
+	}
+
+	close(inputChan)
+
+	go func() {
+		// Wait for the items, so the keys are applied to all of them.
+		for len(u.snapshot()) < len(items) {
+			time.Sleep(time.Millisecond)
+		}
+
+		_, _ = keysW.Write([]byte(keys))
+	}()
+
+	return u.run(context.Background(), inputChan)
+}
@@ -2,37 +2,87 @@ package selector
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"os"
 	"os/exec"
+	"strings"
+	"time"
 )
 
+// Time given to the selector to exit after an interrupt, before it is killed.
+const cancelWaitDelay = time.Second
+
 type Cmd struct {
 	cmd    string
+	args   []string
 	outBuf *bytes.Buffer
 	errBuf *bytes.Buffer
+
+	// Whether the output of stderr is shown to the user, instead of being returned as an error.
+	passStderr bool
+
+	// Terminator of the options written to stdin and of the lines read from stdout.
+	sep byte
 }
 
-func NewCmd(cmd string) Selector {
+func NewCmd(cmd string, args ...string) Selector {
 	return &Cmd{
 		cmd:    cmd,
+		args:   args,
 		outBuf: new(bytes.Buffer),
 		errBuf: new(bytes.Buffer),
+		sep:    '\n',
 	}
 }
 
-func (c *Cmd) Run(inputChan chan string) (string, error) {
-	cmd := exec.Command(c.cmd)
+// NewNulCmd creates a selector exchanging NUL-terminated options with the command,
+// so options may contain newlines. The command must support it (e.g. "fzf --read0 --print0").
+func NewNulCmd(cmd string, args ...string) Selector {
+	c := NewCmd(cmd, args...).(*Cmd)
+	c.sep = 0
+
+	return c
+}
+
+// NewCustom creates a selector running a user-defined command line (e.g. "peco", "gum filter").
+// Its stderr is shown as is, since some selectors draw their interface there.
+func NewCustom(args []string) (Selector, error) {
+	if len(args) == 0 {
+		return nil, errors.New("empty selector command")
+	}
+
+	c := NewCmd(args[0], args[1:]...).(*Cmd)
+	c.passStderr = true
+
+	return c, nil
+}
+
+// Run writes the options to the stdin of the command, one per line (or NUL-terminated),
+// and returns the lines it prints to stdout.
+func (c *Cmd) Run(ctx context.Context, inputChan chan string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, c.cmd, c.args...)
+
+	// Interrupt instead of kill, so the selector can restore the terminal.
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = cancelWaitDelay
 
 	cmd.Stdout = c.outBuf
 	cmd.Stderr = c.errBuf
 
+	if c.passStderr {
+		cmd.Stderr = os.Stderr
+	}
+
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if err := cmd.Start(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	go func() {
@@ -40,23 +90,49 @@ func (c *Cmd) Run(inputChan chan string) (string, error) {
 
 		inputBuf := new(bytes.Buffer)
 
-		for input := range inputChan {
+		for {
+			var input string
+			var ok bool
+
+			select {
+			case input, ok = <-inputChan:
+			case <-ctx.Done():
+				return
+			}
+
+			if !ok {
+				return
+			}
+
 			inputBuf.Reset()
 			inputBuf.WriteString(input)
-			inputBuf.WriteByte('\n')
+			inputBuf.WriteByte(c.sep)
 
-			_, err = stdin.Write(inputBuf.Bytes())
-			if err != nil {
-				c.errBuf.WriteString(err.Error())
+			// The selector exited (e.g. an entry was selected), so there is no one left to read the input.
+			if _, err := stdin.Write(inputBuf.Bytes()); err != nil {
+				return
 			}
 		}
 	}()
 
 	_ = cmd.Wait()
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if c.errBuf.Len() > 0 {
-		return "", errors.New(c.errBuf.String())
+		return nil, errors.New(c.errBuf.String())
+	}
+
+	return splitLines(c.outBuf.String(), c.sep), nil
+}
+
+func splitLines(s string, sep byte) []string {
+	s = strings.TrimSuffix(s, string(sep))
+	if s == "" {
+		return nil
 	}
 
-	return c.outBuf.String(), nil
+	return strings.Split(s, string(sep))
 }
@@ -0,0 +1,126 @@
+package selector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmd(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector func() (Selector, error)
+		expected []string
+		err      bool
+	}{
+		{
+			name: "Arguments",
+			selector: func() (Selector, error) {
+				return NewCmd("sed", "-n", "2p"), nil
+			},
+			expected: []string{"rapid"},
+		},
+		{
+			name: "Custom",
+			selector: func() (Selector, error) {
+				return NewCustom([]string{"grep", "-m", "1", "web"})
+			},
+			expected: []string{"web"},
+		},
+		{
+			name: "Multiple lines",
+			selector: func() (Selector, error) {
+				return NewCustom([]string{"grep", "ap"})
+			},
+			expected: []string{"api-gateway", "rapid"},
+		},
+		{
+			name: "No selection",
+			selector: func() (Selector, error) {
+				return NewCustom([]string{"grep", "billing"})
+			},
+			expected: nil,
+		},
+		{
+			name: "Empty custom",
+			selector: func() (Selector, error) {
+				return NewCustom(nil)
+			},
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.selector()
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			ch := make(chan string, 3)
+			ch <- "api-gateway"
+			ch <- "rapid"
+			ch <- "web"
+			close(ch)
+
+			result, err := s.Run(context.Background(), ch)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNulCmd(t *testing.T) {
+	ch := make(chan string, 2)
+	ch <- "~/src/odd\nname"
+	ch <- "~/src/web"
+	close(ch)
+
+	result, err := NewNulCmd("cat").Run(context.Background(), ch)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"~/src/odd\nname", "~/src/web"}, result)
+}
+
+func TestPreviewArgs(t *testing.T) {
+	assert.Equal(t, []string{"--preview", "gsp preview {}"}, PreviewArgs(TypeFzf, "gsp preview {}"))
+	assert.Equal(t, []string{"--preview", "gsp preview {}"}, PreviewArgs(TypeSkim, "gsp preview {}"))
+	assert.Nil(t, PreviewArgs(TypeFzy, "gsp preview {}"))
+	assert.Nil(t, PreviewArgs(TypeBuiltin, "gsp preview {}"))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(TypeBuiltin, Options{Args: []string{"--reverse"}})
+	assert.Error(t, err)
+
+	_, err = New(TypeBuiltin, Options{Multi: true, Labeled: true})
+	assert.NoError(t, err)
+
+	_, err = New(TypeFzy, Options{Multi: true})
+	assert.Error(t, err)
+
+	_, err = New(TypeFzy, Options{Labeled: true})
+	assert.Error(t, err)
+
+	s, err := New(TypeFzf, Options{Args: []string{"--reverse"}, Multi: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--read0", "--print0", "--multi", "--reverse"}, s.(*Cmd).args)
+
+	s, err = New(TypeSkim, Options{Args: []string{"--with-nth", "3"}, Labeled: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--read0", "--print0", "--delimiter", "\t", "--with-nth", "2..", "--with-nth", "3"}, s.(*Cmd).args)
+
+	_, err = New(TypeBuiltin, Options{Expect: []string{"ctrl-o"}})
+	assert.Error(t, err)
+
+	s, err = New(TypeFzf, Options{Expect: []string{"ctrl-o", "alt-s"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--read0", "--print0", "--expect", "ctrl-o,alt-s"}, s.(*Cmd).args)
+
+	s, err = New(TypeFzy, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, byte('\n'), s.(*Cmd).sep)
+}
@@ -1,6 +1,7 @@
 package selector
 
 import (
+	"context"
 	"fmt"
 	"strings"
 )
@@ -13,6 +14,7 @@ const (
 	TypeFzy
 	TypeFzf
 	TypeSkim
+	TypeBuiltin
 )
 
 func TypeFromStr(s string) (Type, error) {
@@ -23,25 +25,111 @@ func TypeFromStr(s string) (Type, error) {
 		return TypeFzf, nil
 	case "sk":
 		return TypeSkim, nil
+	case "builtin":
+		return TypeBuiltin, nil
 	default:
 		return UnknownType, fmt.Errorf("Invalid selector '%s'", s)
 	}
 }
 
 // Displays a series of options for user selection.
+// It returns the selected options, or none if the selection is canceled.
+// The selector must stop and return the context error once ctx is done.
 type Selector interface {
-	Run(inputChan chan string) (string, error)
+	Run(ctx context.Context, inputChan chan string) ([]string, error)
+}
+
+// LabelSep separates the value of an option from its label, when options are labeled.
+const LabelSep = "\t"
+
+// Options are the settings of a selector.
+type Options struct {
+	// Arguments passed to the selector command. Not supported by the builtin selector.
+	Args []string
+
+	// Whether several options can be selected. Not supported by fzy.
+	Multi bool
+
+	// Whether options are written as "<value>\t<label>", where only the label is displayed and matched.
+	// Selected options are returned as is. Only supported when [SupportsLabels] is true.
+	Labeled bool
+
+	// Keys accepting the selection, besides enter (e.g. "ctrl-o").
+	// The key pressed is then returned before the selected options, empty for enter.
+	// Only supported when [SupportsExpect] is true.
+	Expect []string
+}
+
+// SupportsLabels reports whether the selector type can display labels instead of the full options.
+func SupportsLabels(t Type) bool {
+	return t == TypeFzf || t == TypeSkim || t == TypeBuiltin
+}
+
+// SupportsExpect reports whether the selector type can accept the selection with other keys than enter.
+func SupportsExpect(t Type) bool {
+	return t == TypeFzf || t == TypeSkim
+}
+
+// PreviewArgs returns the arguments displaying the output of cmd for the current entry in a preview pane.
+// "{}" in cmd is replaced by the entry, and "{1}" by its value when labeled. Only fzf and sk support previews;
+// other types return nil.
+func PreviewArgs(t Type, cmd string) []string {
+	switch t {
+	case TypeFzf, TypeSkim:
+		return []string{"--preview", cmd}
+	default:
+		return nil
+	}
 }
 
 // New creates a new Selector instance based on the provided selector type and options.
-func New(t Type) (Selector, error) {
+func New(t Type, opts Options) (Selector, error) {
+	var args []string
+
+	if opts.Multi {
+		switch t {
+		case TypeFzf, TypeSkim:
+			args = append(args, "--multi")
+		case TypeFzy:
+			return nil, fmt.Errorf("The fzy selector does not support multi-select")
+		}
+	}
+
+	if opts.Labeled {
+		if !SupportsLabels(t) {
+			return nil, fmt.Errorf("The selector does not support labels")
+		}
+
+		// The first field is the value.
+		if t != TypeBuiltin {
+			args = append(args, "--delimiter", LabelSep, "--with-nth", "2..")
+		}
+	}
+
+	if len(opts.Expect) > 0 {
+		if !SupportsExpect(t) {
+			return nil, fmt.Errorf("The selector does not support expected keys")
+		}
+
+		args = append(args, "--expect", strings.Join(opts.Expect, ","))
+	}
+
+	// Added last, so they can override the other arguments.
+	args = append(args, opts.Args...)
+
 	switch t {
 	case TypeFzf:
-		return NewCmd("fzf"), nil
+		return NewNulCmd("fzf", append([]string{"--read0", "--print0"}, args...)...), nil
 	case TypeFzy:
-		return NewCmd("fzy"), nil
+		return NewCmd("fzy", args...), nil
 	case TypeSkim:
-		return NewCmd("sk"), nil
+		return NewNulCmd("sk", append([]string{"--read0", "--print0"}, args...)...), nil
+	case TypeBuiltin:
+		if len(opts.Args) > 0 {
+			return nil, fmt.Errorf("The builtin selector does not accept arguments")
+		}
+
+		return NewBuiltin(opts), nil
 	default:
 		return nil, fmt.Errorf("Failed to start selector")
 	}
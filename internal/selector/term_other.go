@@ -0,0 +1,18 @@
+//go:build !linux && !darwin
+
+package selector
+
+import (
+	"errors"
+	"os"
+)
+
+var errTermUnsupported = errors.New("the built-in selector is not supported on this platform")
+
+func makeRaw(tty *os.File) (func() error, error) {
+	return nil, errTermUnsupported
+}
+
+func termSize(tty *os.File) (int, int, error) {
+	return 0, 0, errTermUnsupported
+}
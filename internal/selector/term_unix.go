@@ -0,0 +1,72 @@
+//go:build linux || darwin
+
+package selector
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// makeRaw puts the terminal into raw mode, returning a function to restore its previous state.
+func makeRaw(tty *os.File) (func() error, error) {
+	var old syscall.Termios
+	if err := ioctl(tty, ioctlGetTermios, unsafe.Pointer(&old)); err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err := ioctl(tty, ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return ioctl(tty, ioctlSetTermios, unsafe.Pointer(&old))
+	}, nil
+}
+
+// termSize returns the number of columns and rows of the terminal.
+func termSize(tty *os.File) (int, int, error) {
+	var ws struct {
+		Row, Col, X, Y uint16
+	}
+
+	if err := ioctl(tty, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, err
+	}
+
+	return int(ws.Col), int(ws.Row), nil
+}
+
+// ioctl runs without calling tty.Fd, which would put the file into blocking mode
+// and prevent Close from interrupting a pending Read.
+func ioctl(tty *os.File, req uint, arg unsafe.Pointer) error {
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var errno syscall.Errno
+
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(req), uintptr(arg))
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
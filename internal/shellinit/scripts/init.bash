@@ -0,0 +1,27 @@
+# gsp shell integration for bash.
+# Add to your .bashrc: eval "$(gsp init bash)"
+
+{{.Name}}() {
+    local dir
+    dir="$(command gsp "$@")" && [ -n "$dir" ] && builtin cd -- "$dir"
+}
+{{- if .Key}}
+
+# Jumps to the selected project, or inserts its path when the command line is not empty.
+__{{.Name}}_widget() {
+    local dir
+    dir="$(command gsp)" && [ -n "$dir" ] || return
+
+    if [ -z "$READLINE_LINE" ]; then
+        builtin cd -- "$dir"
+        return
+    fi
+
+    local quoted
+    printf -v quoted '%q' "$dir"
+    READLINE_LINE="${READLINE_LINE:0:$READLINE_POINT}$quoted${READLINE_LINE:$READLINE_POINT}"
+    READLINE_POINT=$((READLINE_POINT + ${#quoted}))
+}
+
+bind -x '"{{.Key}}": __{{.Name}}_widget'
+{{- end}}
@@ -0,0 +1,27 @@
+# gsp shell integration for fish.
+# Add to your config.fish: gsp init fish | source
+
+function {{.Name}}
+    set -l dir (command gsp $argv)
+    and test -n "$dir"
+    and cd -- $dir
+end
+{{- if .Key}}
+
+# Jumps to the selected project, or inserts its path when the command line is not empty.
+function __{{.Name}}_widget
+    set -l dir (command gsp)
+
+    if test -n "$dir"
+        if test -z (commandline)
+            cd -- $dir
+        else
+            commandline -i -- (string escape -- $dir)
+        end
+    end
+
+    commandline -f repaint
+end
+
+bind {{.Key}} __{{.Name}}_widget
+{{- end}}
@@ -0,0 +1,32 @@
+# gsp shell integration for PowerShell.
+# Add to your profile: Invoke-Expression (& gsp init powershell | Out-String)
+
+function {{.Name}} {
+    $dir = & gsp @args
+
+    if ($LASTEXITCODE -eq 0 -and $dir) {
+        Set-Location -LiteralPath $dir
+    }
+}
+{{- if .Key}}
+
+# Jumps to the selected project, or inserts its path when the command line is not empty.
+Set-PSReadLineKeyHandler -Chord '{{.Key}}' -ScriptBlock {
+    $line = $null
+    $cursor = $null
+    [Microsoft.PowerShell.PSConsoleReadLine]::GetBufferState([ref]$line, [ref]$cursor)
+
+    $dir = & gsp
+    if ($LASTEXITCODE -ne 0 -or -not $dir) {
+        [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+        return
+    }
+
+    if ($line) {
+        [Microsoft.PowerShell.PSConsoleReadLine]::Insert("'" + $dir.Replace("'", "''") + "'")
+    } else {
+        Set-Location -LiteralPath $dir
+        [Microsoft.PowerShell.PSConsoleReadLine]::InvokePrompt()
+    }
+}
+{{- end}}
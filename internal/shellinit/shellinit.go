@@ -0,0 +1,129 @@
+// Package shellinit generates the shell integration printed by "gsp init".
+package shellinit
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//go:embed scripts
+var scripts embed.FS
+
+// Shells are the supported shells, with the name of their script.
+var Shells = map[string]string{
+	"bash":       "init.bash",
+	"zsh":        "init.zsh",
+	"fish":       "init.fish",
+	"nushell":    "init.nu",
+	"powershell": "init.ps1",
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// Options are the settings of the generated script.
+type Options struct {
+	// Name of the function running gsp and changing to the selected directory.
+	Name string
+
+	// Key binding, as "ctrl-<letter>" or "alt-<letter>". Optional.
+	Key string
+}
+
+// data is passed to the script templates.
+type data struct {
+	Name string
+
+	// Key in the notation of the shell. Empty when no key is bound.
+	Key string
+
+	// Key for nushell, which sets the modifier and the key separately.
+	KeyModifier string
+	KeyCode     string
+}
+
+// Script returns the integration script for shell.
+func Script(shell string, opts Options) (string, error) {
+	file, ok := Shells[shell]
+	if !ok {
+		return "", fmt.Errorf("unsupported shell %q (available options: bash, zsh, fish, nushell, powershell)", shell)
+	}
+
+	if !validName.MatchString(opts.Name) {
+		return "", fmt.Errorf("invalid function name %q", opts.Name)
+	}
+
+	d := data{Name: opts.Name}
+
+	if opts.Key != "" {
+		mod, letter, err := parseKey(opts.Key)
+		if err != nil {
+			return "", err
+		}
+
+		d.Key = formatKey(shell, mod, letter)
+		d.KeyModifier = map[string]string{"ctrl": "control", "alt": "alt"}[mod]
+		d.KeyCode = "char_" + letter
+	}
+
+	tmpl, err := template.ParseFS(scripts, "scripts/"+file)
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, d); err != nil {
+		return "", err
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.String(), nil
+}
+
+// parseKey splits a key like "ctrl-g" into its modifier and letter.
+func parseKey(key string) (mod, letter string, err error) {
+	mod, letter, _ = strings.Cut(strings.ToLower(key), "-")
+
+	if (mod != "ctrl" && mod != "alt") || len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+		return "", "", fmt.Errorf("invalid key %q (expected ctrl-<letter> or alt-<letter>)", key)
+	}
+
+	return mod, letter, nil
+}
+
+func formatKey(shell, mod, letter string) string {
+	ctrl := mod == "ctrl"
+
+	switch shell {
+	case "bash":
+		if ctrl {
+			return `\C-` + letter
+		}
+
+		return `\e` + letter
+	case "zsh":
+		if ctrl {
+			return "^" + strings.ToUpper(letter)
+		}
+
+		return "^[" + letter
+	case "fish":
+		if ctrl {
+			return `\c` + letter
+		}
+
+		return `\e` + letter
+	case "powershell":
+		if ctrl {
+			return "Ctrl+" + letter
+		}
+
+		return "Alt+" + letter
+	default:
+		return mod + "-" + letter
+	}
+}
@@ -0,0 +1,80 @@
+package shellinit
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScript(t *testing.T) {
+	tests := []struct {
+		shell    string
+		key      string
+		contains []string
+	}{
+		{shell: "bash", contains: []string{"proj() {", `command gsp "$@"`}},
+		{shell: "bash", key: "ctrl-g", contains: []string{`bind -x '"\C-g": __proj_widget'`}},
+		{shell: "bash", key: "alt-p", contains: []string{`bind -x '"\ep": __proj_widget'`}},
+		{shell: "zsh", key: "ctrl-g", contains: []string{"zle -N __proj_widget", "bindkey '^G' __proj_widget"}},
+		{shell: "fish", key: "Ctrl-G", contains: []string{"function proj", `bind \cg __proj_widget`}},
+		{shell: "nushell", key: "alt-g", contains: []string{"def --env --wrapped proj", "modifier: alt", "keycode: char_g"}},
+		{shell: "powershell", key: "ctrl-g", contains: []string{"function proj", "-Chord 'Ctrl+g'"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell+" "+tt.key, func(t *testing.T) {
+			script, err := Script(tt.shell, Options{Name: "proj", Key: tt.key})
+			assert.NoError(t, err)
+
+			for _, s := range tt.contains {
+				assert.Contains(t, script, s)
+			}
+
+			// The binding is only appended when a key is given.
+			plain, err := Script(tt.shell, Options{Name: "proj"})
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(script, strings.TrimSuffix(plain, "\n")))
+			assert.Equal(t, tt.key != "", len(script) > len(plain))
+		})
+	}
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := Script("tcsh", Options{Name: "sp"})
+		assert.ErrorContains(t, err, "unsupported shell")
+
+		_, err = Script("bash", Options{Name: "sp; rm -rf ~"})
+		assert.ErrorContains(t, err, "invalid function name")
+
+		for _, key := range []string{"g", "ctrl-", "ctrl-gg", "shift-g", "ctrl-1"} {
+			_, err = Script("bash", Options{Name: "sp", Key: key})
+			assert.ErrorContains(t, err, "invalid key", key)
+		}
+	})
+}
+
+func TestScriptSyntax(t *testing.T) {
+	shells := map[string][]string{
+		"bash": {"bash", "-n"},
+		"zsh":  {"zsh", "-n"},
+		"fish": {"fish", "--no-execute"},
+	}
+
+	for shell, args := range shells {
+		t.Run(shell, func(t *testing.T) {
+			if _, err := exec.LookPath(args[0]); err != nil {
+				t.Skipf("%s is not installed", args[0])
+			}
+
+			script, err := Script(shell, Options{Name: "sp", Key: "ctrl-g"})
+			assert.NoError(t, err)
+
+			cmd := exec.Command(args[0], args[1:]...)
+			cmd.Stdin = strings.NewReader(script)
+
+			out, err := cmd.CombinedOutput()
+			assert.NoError(t, err, string(out))
+		})
+	}
+}
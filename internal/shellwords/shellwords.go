@@ -0,0 +1,108 @@
+// Package shellwords splits and quotes command line arguments, following POSIX shell quoting.
+//
+// Only quoting is handled. Variables, globs and other expansions are left as is.
+package shellwords
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+var ErrTrailingBackslash = errors.New("trailing backslash")
+
+// Split splits s into arguments separated by unquoted whitespace.
+//
+// Single quotes preserve their content literally. Inside double quotes, a backslash
+// only escapes '"', '\', '$' and '`'. Outside quotes, a backslash escapes any character.
+func Split(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+
+	// Whether an argument was started, so that quoted empty strings are kept.
+	inArg := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		case '\\':
+			i++
+			if i == len(s) {
+				return nil, ErrTrailingBackslash
+			}
+
+			arg.WriteByte(s[i])
+			inArg = true
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end == -1 {
+				return nil, ErrUnterminatedQuote
+			}
+
+			arg.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case '"':
+			n, err := doubleQuoted(s[i+1:], &arg)
+			if err != nil {
+				return nil, err
+			}
+
+			i += n + 1
+			inArg = true
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
+
+// doubleQuoted writes the content of a double-quoted string to arg.
+// s starts right after the opening quote. It returns the index of the closing quote.
+func doubleQuoted(s string, arg *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return i, nil
+		case '\\':
+			if i+1 < len(s) && strings.IndexByte("\"\\$`", s[i+1]) != -1 {
+				i++
+			}
+		}
+
+		arg.WriteByte(s[i])
+	}
+
+	return 0, ErrUnterminatedQuote
+}
+
+// Quote quotes s so that a shell reads it as a single argument.
+func Quote(s string) string {
+	if s != "" && strings.IndexFunc(s, needsQuote) == -1 {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func needsQuote(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return false
+	}
+
+	return !strings.ContainsRune("-_./:@%+=,", r)
+}
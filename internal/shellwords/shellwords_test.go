@@ -0,0 +1,61 @@
+package shellwords
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+		err      error
+	}{
+		{input: "", expected: nil},
+		{input: "  \t ", expected: nil},
+		{input: "fzf", expected: []string{"fzf"}},
+		{input: "fzf --height 40% --reverse", expected: []string{"fzf", "--height", "40%", "--reverse"}},
+		{input: "fzf --preview 'ls {}'", expected: []string{"fzf", "--preview", "ls {}"}},
+		{input: `gum filter --placeholder "Pick a project"`, expected: []string{"gum", "filter", "--placeholder", "Pick a project"}},
+		{input: `rofi -dmenu -p "\"gsp\" \$ \n"`, expected: []string{"rofi", "-dmenu", "-p", `"gsp" $ \n`}},
+		{input: `a\ b 'c'"d"e`, expected: []string{"a b", "cde"}},
+		{input: `'' ""`, expected: []string{"", ""}},
+		{input: `'it''s'`, expected: []string{"its"}},
+		{input: "fzf --preview 'ls", err: ErrUnterminatedQuote},
+		{input: `fzf "--reverse`, err: ErrUnterminatedQuote},
+		{input: `fzf \`, err: ErrTrailingBackslash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			args, err := Split(tt.input)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, args)
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "", expected: "''"},
+		{input: "/usr/local/bin/gsp", expected: "/usr/local/bin/gsp"},
+		{input: "/home/me/my tools/gsp", expected: "'/home/me/my tools/gsp'"},
+		{input: "it's", expected: `'it'\''s'`},
+		{input: "$HOME", expected: "'$HOME'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			quoted := Quote(tt.input)
+			assert.Equal(t, tt.expected, quoted)
+
+			args, err := Split(quoted)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{tt.input}, args)
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Package tmux opens tmux sessions for projects.
+package tmux
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gabefiori/gsp/internal/shellwords"
+)
+
+// Session is an existing tmux session.
+type Session struct {
+	Name string
+
+	// Working directory the session was created with.
+	Path string
+}
+
+// Options are the settings used to open sessions.
+type Options struct {
+	// Layout file applied to new sessions, relative to the project directory. Optional.
+	Layout string
+}
+
+// Open creates a session for each directory, unless one already exists,
+// then attaches to the session of the first directory (or switches to it, inside tmux).
+func Open(ctx context.Context, dirs []string, opts Options) error {
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return err
+	}
+
+	sessions, err := List(ctx)
+	if err != nil {
+		return err
+	}
+
+	var first string
+
+	for _, dir := range dirs {
+		name, exists, err := SessionName(dir, sessions)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			if err := run(ctx, "", "new-session", "-d", "-s", name, "-c", dir); err != nil {
+				return err
+			}
+
+			sessions = append(sessions, Session{Name: name, Path: dir})
+
+			if opts.Layout != "" {
+				if err := applyLayout(ctx, name, dir, opts.Layout); err != nil {
+					return err
+				}
+			}
+		}
+
+		if first == "" {
+			first = name
+		}
+	}
+
+	if os.Getenv("TMUX") != "" {
+		return run(ctx, "", "switch-client", "-t", "="+first)
+	}
+
+	cmd := exec.CommandContext(ctx, "tmux", "attach-session", "-t", "="+first)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("tmux attach-session: %w", err)
+	}
+
+	return nil
+}
+
+// List returns the existing sessions. Without a running server, there are none.
+func List(ctx context.Context) ([]Session, error) {
+	// Session names cannot contain ':', unlike paths. Tabs are not used, since some versions replace them.
+	out, err := exec.CommandContext(ctx, "tmux", "list-sessions", "-F", "#{session_name}:#{session_path}").Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var sessions []Session
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		name, path, _ := strings.Cut(scanner.Text(), ":")
+		sessions = append(sessions, Session{Name: name, Path: path})
+	}
+
+	return sessions, scanner.Err()
+}
+
+// SessionName returns the name of the session for dir, and whether it already exists.
+//
+// The name is the base name of dir. When a session of another directory already uses it,
+// parent directories are prepended (e.g. "work/api" and "personal/api"), then a number.
+// An error is returned when every name is used by other directories.
+func SessionName(dir string, sessions []Session) (string, bool, error) {
+	candidates := candidateNames(dir)
+
+	taken := make(map[string]string, len(sessions))
+	for _, s := range sessions {
+		taken[s.Name] = s.Path
+	}
+
+	// Sessions are reused even if a shorter name was freed since they were created.
+	for _, name := range candidates {
+		if path, ok := taken[name]; ok && path == dir {
+			return name, true, nil
+		}
+	}
+
+	for _, name := range candidates {
+		if _, ok := taken[name]; !ok {
+			return name, false, nil
+		}
+	}
+
+	return "", false, fmt.Errorf("no session name available for %s", dir)
+}
+
+// candidateNames returns the possible session names for dir, shortest first.
+func candidateNames(dir string) []string {
+	var parts []string
+	for _, p := range strings.Split(filepath.ToSlash(filepath.Clean(dir)), "/") {
+		if p != "" {
+			parts = append(parts, sanitize(p))
+		}
+	}
+
+	if len(parts) == 0 {
+		parts = []string{"root"}
+	}
+
+	var names []string
+	for i := len(parts) - 1; i >= 0; i-- {
+		names = append(names, strings.Join(parts[i:], "/"))
+	}
+
+	// Sanitized names may still collide (e.g. "a.b" and "a_b").
+	full := names[len(names)-1]
+	for i := 2; i <= 100; i++ {
+		names = append(names, full+"-"+strconv.Itoa(i))
+	}
+
+	return names
+}
+
+// sanitize replaces the characters tmux does not allow in session names ('.' and ':'),
+// along with spaces and control characters, which make targets hard to type.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == ':' || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return '_'
+		}
+
+		return r
+	}, s)
+}
+
+// applyLayout runs the tmux commands of the layout file, one per line, in the session.
+// "{session}" and "{path}" in the commands are replaced by the session name and the project directory.
+func applyLayout(ctx context.Context, name, dir, layout string) error {
+	if !filepath.IsAbs(layout) {
+		layout = filepath.Join(dir, layout)
+	}
+
+	data, err := os.ReadFile(layout)
+	if err != nil {
+		// Projects without a layout file get the default session.
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	replacer := strings.NewReplacer("{session}", name, "{path}", dir)
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		args, err := shellwords.Split(line)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", layout, i+1, err)
+		}
+
+		for j, arg := range args {
+			args[j] = replacer.Replace(arg)
+		}
+
+		if err := run(ctx, dir, args...); err != nil {
+			return fmt.Errorf("%s:%d: %w", layout, i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// run executes a tmux command, returning its error output as the error.
+func run(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "tmux", args...)
+	cmd.Dir = dir
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("tmux %s: %s", args[0], msg)
+		}
+
+		return fmt.Errorf("tmux %s: %w", args[0], err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package tmux
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionName(t *testing.T) {
+	tests := []struct {
+		name     string
+		dir      string
+		sessions []Session
+		expected string
+		exists   bool
+		err      bool
+	}{
+		{
+			name:     "Base name",
+			dir:      "/home/you/work/api",
+			expected: "api",
+		},
+		{
+			name:     "Existing",
+			dir:      "/home/you/work/api",
+			sessions: []Session{{Name: "api", Path: "/home/you/work/api"}},
+			expected: "api",
+			exists:   true,
+		},
+		{
+			name:     "Same base name",
+			dir:      "/home/you/personal/api",
+			sessions: []Session{{Name: "api", Path: "/home/you/work/api"}},
+			expected: "personal/api",
+		},
+		{
+			name: "Existing with a longer name",
+			dir:  "/home/you/personal/api",
+			sessions: []Session{
+				{Name: "personal/api", Path: "/home/you/personal/api"},
+			},
+			expected: "personal/api",
+			exists:   true,
+		},
+		{
+			name:     "Sanitized",
+			dir:      "/home/you/my.app: v2",
+			expected: "my_app__v2",
+		},
+		{
+			name: "Same sanitized name",
+			dir:  "/a_b",
+			sessions: []Session{
+				{Name: "a_b", Path: "/a.b"},
+			},
+			expected: "a_b-2",
+		},
+		{
+			name:     "Root",
+			dir:      "/",
+			expected: "root",
+		},
+		{
+			name:     "All names taken",
+			dir:      "/a_b",
+			sessions: takenSessions("a_b", "/a.b"),
+			err:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, exists, err := SessionName(tt.dir, tt.sessions)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+			assert.Equal(t, tt.exists, exists)
+		})
+	}
+}
+
+// takenSessions returns sessions of dir using name and all its numbered variants.
+func takenSessions(name, dir string) []Session {
+	sessions := []Session{{Name: name, Path: dir}}
+	for i := 2; i <= 100; i++ {
+		sessions = append(sessions, Session{Name: name + "-" + strconv.Itoa(i), Path: dir})
+	}
+
+	return sessions
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	sessions := filepath.Join(dir, "sessions")
+
+	// Records the arguments of every call, and lists the sessions of the sessions file.
+	script := `#!/bin/sh
+echo "$*" >> "` + log + `"
+if [ "$1" = list-sessions ]; then
+	cat "` + sessions + `" 2>/dev/null || exit 1
+fi
+`
+
+	bin := filepath.Join(dir, "bin")
+	assert.NoError(t, os.Mkdir(bin, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "tmux"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	work := filepath.Join(dir, "work", "api")
+	personal := filepath.Join(dir, "personal", "api")
+	assert.NoError(t, os.MkdirAll(work, 0755))
+	assert.NoError(t, os.MkdirAll(personal, 0755))
+
+	layout := "# Editor on the first window.\n\nnew-window -t {session} -n 'my editor' -c {path}\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(personal, ".tmux-layout"), []byte(layout), 0644))
+
+	calls := func() []string {
+		t.Helper()
+
+		data, err := os.ReadFile(log)
+		assert.NoError(t, err)
+		assert.NoError(t, os.Remove(log))
+
+		return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	listSessions := "list-sessions -F #{session_name}:#{session_path}"
+
+	t.Run("Attach", func(t *testing.T) {
+		t.Setenv("TMUX", "")
+
+		assert.NoError(t, Open(context.Background(), []string{work}, Options{Layout: ".tmux-layout"}))
+		assert.Equal(t, []string{
+			listSessions,
+			"new-session -d -s api -c " + work,
+			"attach-session -t =api",
+		}, calls())
+	})
+
+	t.Run("Switch", func(t *testing.T) {
+		t.Setenv("TMUX", "/tmp/tmux-1000/default,1,0")
+		assert.NoError(t, os.WriteFile(sessions, []byte("api:"+work+"\n"), 0644))
+
+		assert.NoError(t, Open(context.Background(), []string{personal, work}, Options{Layout: ".tmux-layout"}))
+		assert.Equal(t, []string{
+			listSessions,
+			"new-session -d -s personal/api -c " + personal,
+			"new-window -t personal/api -n my editor -c " + personal,
+			"switch-client -t =personal/api",
+		}, calls())
+	})
+}